@@ -0,0 +1,161 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+//go:embed resources
+var embeddedResources embed.FS
+
+// OpenAssets returns the filesystem sprites and data files are loaded from.
+// An empty dir means the resources baked into the binary; otherwise assets
+// are read from dir so they can be swapped out without rebuilding.
+func OpenAssets(dir string) (fs.FS, error) {
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, err
+		}
+		return os.DirFS(dir), nil
+	}
+	return fs.Sub(embeddedResources, "resources")
+}
+
+// AssetManager decodes each image once and hands the same *ebiten.Image to
+// every scene that asks for it. With Watch running, changed files are
+// redrawn into the existing images so sprites update in place.
+type AssetManager struct {
+	fsys   fs.FS
+	source string
+
+	images   map[string]*ebiten.Image
+	modTimes map[string]time.Time
+	// by path and size
+	faces map[string]font.Face
+
+	mu       sync.Mutex
+	reloaded []string
+}
+
+func NewAssetManager(fsys fs.FS, source string) *AssetManager {
+	return &AssetManager{
+		fsys:     fsys,
+		source:   source,
+		images:   make(map[string]*ebiten.Image),
+		modTimes: make(map[string]time.Time),
+		faces:    make(map[string]font.Face),
+	}
+}
+
+func (a *AssetManager) missingError(filepath string, err error) error {
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	dir := path.Dir(filepath)
+	entries, dirErr := fs.ReadDir(a.fsys, dir)
+	if dirErr != nil {
+		return fmt.Errorf("asset %q not found in %s (directory %q doesn't exist either)", filepath, a.source, dir)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return fmt.Errorf("asset %q not found in %s; %q contains: %s", filepath, a.source, dir, strings.Join(names, ", "))
+}
+
+func (a *AssetManager) ReadFile(filepath string) ([]byte, error) {
+	raw, err := fs.ReadFile(a.fsys, filepath)
+	if err != nil {
+		return nil, a.missingError(filepath, err)
+	}
+	return raw, nil
+}
+
+func (a *AssetManager) Image(filepath string) (*ebiten.Image, error) {
+	if img, ok := a.images[filepath]; ok {
+		return img, nil
+	}
+	decoded, err := decodeImage(a.fsys, filepath)
+	if err != nil {
+		return nil, a.missingError(filepath, err)
+	}
+	img := ebiten.NewImageFromImage(decoded)
+	a.images[filepath] = img
+	a.mu.Lock()
+	a.modTimes[filepath] = a.modTime(filepath)
+	a.mu.Unlock()
+	return img, nil
+}
+
+func (a *AssetManager) Spritemap(filepath string, imW, imH, tilesW, tilesH, paddingW, paddingH int) ([]*ebiten.Image, error) {
+	img, err := a.Image(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return sliceSpritemap(img, imW, imH, tilesW, tilesH, paddingW, paddingH), nil
+}
+
+func (a *AssetManager) modTime(filepath string) time.Time {
+	info, err := fs.Stat(a.fsys, filepath)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Watch polls loaded images for changes, forever. The embedded filesystem
+// has no modification times, so this only does anything when assets come
+// from a directory.
+func (a *AssetManager) Watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		a.mu.Lock()
+		for filepath, lastMod := range a.modTimes {
+			if mod := a.modTime(filepath); mod.After(lastMod) {
+				a.modTimes[filepath] = mod
+				a.reloaded = append(a.reloaded, filepath)
+			}
+		}
+		a.mu.Unlock()
+	}
+}
+
+// Update applies reloads found by Watch. It must run on the game loop since
+// it writes to ebiten images.
+func (a *AssetManager) Update() {
+	a.mu.Lock()
+	reloaded := a.reloaded
+	a.reloaded = nil
+	a.mu.Unlock()
+
+	for _, filepath := range reloaded {
+		img := a.images[filepath]
+		decoded, err := decodeImage(a.fsys, filepath)
+		if err != nil {
+			log.Printf("hot reload of %s failed: %v", filepath, err)
+			continue
+		}
+		if decoded.Bounds().Size() != img.Bounds().Size() {
+			log.Printf("hot reload of %s skipped: size changed from %v to %v, restart to pick it up", filepath, img.Bounds().Size(), decoded.Bounds().Size())
+			continue
+		}
+		rgba := image.NewRGBA(image.Rectangle{Max: decoded.Bounds().Size()})
+		draw.Draw(rgba, rgba.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+		img.ReplacePixels(rgba.Pix)
+		log.Printf("reloaded %s", filepath)
+	}
+}
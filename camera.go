@@ -0,0 +1,173 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/sim"
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+const (
+	cameraFollowDeadzone = playerCameraMaxDist
+	cameraSmoothTime     = 0.35 // seconds to mostly catch up with the target
+	cameraBoundsMargin   = 2
+
+	cameraMinZoom   = 0.25
+	cameraMaxZoom   = 2
+	cameraZoomStep  = 0.1
+	cameraZoomSpeed = 10 // per second, exponential approach to target zoom
+
+	cameraShakeDecay  = 1.5 // trauma lost per second
+	cameraShakeMaxOff = 0.3 // tiles at full trauma
+)
+
+type cameraPan struct {
+	target    world.IsometricCoordinate
+	holdTicks int
+	arrived   bool
+}
+
+// Camera chases a follow target with a critically damped spring and writes
+// the result, plus any shake, into its Viewport once per tick.
+type Camera struct {
+	view *Viewport
+
+	pos, vel   world.IsometricCoordinate
+	follow     world.IsometricCoordinate
+	targetZoom float64
+
+	hasBounds            bool
+	boundsMin, boundsMax world.IsometricCoordinate
+
+	pans []*cameraPan
+
+	trauma float64
+	ticks  int
+}
+
+func NewCamera(view *Viewport) *Camera {
+	return &Camera{
+		view:       view,
+		pos:        view.pos,
+		follow:     view.pos,
+		targetZoom: view.zoom,
+	}
+}
+
+func (c *Camera) Viewport() *Viewport {
+	return c.view
+}
+
+// SnapTo jumps straight to pos, skipping smoothing
+func (c *Camera) SnapTo(pos world.IsometricCoordinate) {
+	c.pos = c.clamp(pos)
+	c.follow = c.pos
+	c.vel = world.IsometricCoordinate{}
+	c.view.pos = c.pos
+}
+
+// Follow moves the follow target only once pos leaves the deadzone around it
+func (c *Camera) Follow(pos world.IsometricCoordinate) {
+	dx, dy := pos.X-c.follow.X, pos.Y-c.follow.Y
+	dist := math.Hypot(dx, dy)
+	if dist > cameraFollowDeadzone {
+		c.follow.X += dx / dist * (dist - cameraFollowDeadzone)
+		c.follow.Y += dy / dist * (dist - cameraFollowDeadzone)
+	}
+	c.follow.Z = pos.Z
+}
+
+func (c *Camera) SetBounds(tiles []*world.Tile) {
+	c.hasBounds = false
+	for _, tile := range tiles {
+		if tile.Water {
+			continue
+		}
+		if !c.hasBounds {
+			c.boundsMin, c.boundsMax = tile.Coord, tile.Coord
+			c.hasBounds = true
+			continue
+		}
+		c.boundsMin.X = math.Min(c.boundsMin.X, tile.Coord.X)
+		c.boundsMin.Y = math.Min(c.boundsMin.Y, tile.Coord.Y)
+		c.boundsMax.X = math.Max(c.boundsMax.X, tile.Coord.X)
+		c.boundsMax.Y = math.Max(c.boundsMax.Y, tile.Coord.Y)
+	}
+}
+
+func (c *Camera) clamp(pos world.IsometricCoordinate) world.IsometricCoordinate {
+	if !c.hasBounds {
+		return pos
+	}
+	pos.X = math.Max(c.boundsMin.X-cameraBoundsMargin, math.Min(c.boundsMax.X+cameraBoundsMargin, pos.X))
+	pos.Y = math.Max(c.boundsMin.Y-cameraBoundsMargin, math.Min(c.boundsMax.Y+cameraBoundsMargin, pos.Y))
+	return pos
+}
+
+func (c *Camera) ZoomBy(steps float64) {
+	c.targetZoom = math.Max(cameraMinZoom, math.Min(cameraMaxZoom, c.targetZoom*(1+steps*cameraZoomStep)))
+}
+
+// Shake adds trauma in [0, 1]; the offset scales with trauma squared so small
+// knocks stay subtle
+func (c *Camera) Shake(trauma float64) {
+	c.trauma = math.Min(1, c.trauma+trauma)
+}
+
+// PanTo queues a scripted pan to target, holding there before returning to
+// the follow target. Pans ignore bounds so they can reach the open water.
+func (c *Camera) PanTo(target world.IsometricCoordinate, hold time.Duration) {
+	c.pans = append(c.pans, &cameraPan{
+		target:    target,
+		holdTicks: sim.DurationTicks(hold),
+	})
+}
+
+func (c *Camera) Panning() bool {
+	return len(c.pans) > 0
+}
+
+func smoothDamp(current, target float64, vel *float64, smoothTime, dt float64) float64 {
+	omega := 2 / smoothTime
+	x := omega * dt
+	exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+	change := current - target
+	temp := (*vel + omega*change) * dt
+	*vel = (*vel - omega*temp) * exp
+	return target + (change+temp)*exp
+}
+
+func (c *Camera) Update() {
+	c.ticks++
+
+	target := c.clamp(c.follow)
+	if len(c.pans) > 0 {
+		pan := c.pans[0]
+		target = pan.target
+		if !pan.arrived && math.Hypot(c.pos.X-target.X, c.pos.Y-target.Y) < 0.1 {
+			pan.arrived = true
+		}
+		if pan.arrived {
+			pan.holdTicks--
+			if pan.holdTicks <= 0 {
+				c.pans = c.pans[1:]
+			}
+		}
+	}
+
+	c.pos.X = smoothDamp(c.pos.X, target.X, &c.vel.X, cameraSmoothTime, sim.TickDt)
+	c.pos.Y = smoothDamp(c.pos.Y, target.Y, &c.vel.Y, cameraSmoothTime, sim.TickDt)
+	c.pos.Z = smoothDamp(c.pos.Z, target.Z, &c.vel.Z, cameraSmoothTime, sim.TickDt)
+
+	c.view.zoom += (c.targetZoom - c.view.zoom) * math.Min(1, cameraZoomSpeed*sim.TickDt)
+
+	c.trauma = math.Max(0, c.trauma-cameraShakeDecay*sim.TickDt)
+	shake := c.trauma * c.trauma * cameraShakeMaxOff
+	t := float64(c.ticks)
+	c.view.pos = world.IsometricCoordinate{
+		X: c.pos.X + shake*math.Sin(t*0.9)*math.Cos(t*0.37),
+		Y: c.pos.Y + shake*math.Sin(t*1.1+1.7)*math.Cos(t*0.29),
+		Z: c.pos.Z,
+	}
+}
@@ -0,0 +1,38 @@
+// mapgen runs a map generator without a window and dumps what it made, for
+// tuning generators and for CI, which has no display to open one in
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+func main() {
+	resourceDir := flag.String("resources", "resources", "directory biomes.json is read from")
+	seed := flag.Int64("seed", time.Now().Unix(), "world seed")
+	mapgen := flag.String("mapgen", world.DefaultMapGenerator, "map generator: "+world.MapGeneratorNames())
+	outDir := flag.String("out", "mapgen-out", "directory to write step images, height and tile maps and stats to")
+	flag.Parse()
+
+	generator, err := world.LookupMapGenerator(*mapgen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	biomesPath := filepath.Join(*resourceDir, "biomes.json")
+	raw, err := os.ReadFile(biomesPath)
+	if err != nil {
+		log.Fatalf("loading biomes: %v", err)
+	}
+	biomes, err := world.ParseBiomes(raw, biomesPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := world.DumpMapGeneration(biomes, *mapgen, generator, *seed, world.DefaultMapParams, *outDir); err != nil {
+		log.Fatalf("dumping map generation: %v", err)
+	}
+}
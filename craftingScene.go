@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+const craftedFlashTime = 1500 * time.Millisecond
+
+// craftingSceneImpl is an overlay listing recipes over the game scene, which
+// keeps running underneath but gets no input while it's open. Crafting
+// itself happens in the session, the scene only asks for it.
+type craftingSceneImpl struct {
+	baseScene
+	session  *sim.Session
+	ui       *UI
+	selected int
+
+	// "crafted ..." shows until flashExpires
+	flash        string
+	flashExpires time.Time
+	unsubscribe  func()
+}
+
+func NewCraftingScene(game *Game, session *sim.Session) (Scene, error) {
+	c := &craftingSceneImpl{
+		baseScene: NewBaseScene(game),
+		session:   session,
+		ui:        NewUI(game.input, game.Fonts()),
+	}
+	c.ui.Keyboard = true
+	return c, nil
+}
+
+func (c *craftingSceneImpl) Start() error {
+	c.unsubscribe = sim.Subscribe(c.game.events, func(e sim.ItemCrafted) {
+		for _, r := range sim.Recipes {
+			if r.Item == e.Item {
+				c.flash = fmt.Sprintf("Crafted: %s", r.Name)
+				c.flashExpires = c.clock.Now().Add(craftedFlashTime)
+			}
+		}
+	})
+	return nil
+}
+
+func (c *craftingSceneImpl) Stop() error {
+	c.unsubscribe()
+	return nil
+}
+
+func (c *craftingSceneImpl) Update() error {
+	if err := c.baseScene.Update(); err != nil {
+		return err
+	}
+	input := c.game.input
+	crafting, progress := c.session.Crafting()
+	if crafting == nil && (input.JustPressed(sim.ActionCraft) || input.JustPressed(sim.ActionMenu)) {
+		input.Consume(sim.AllActions...)
+		return c.game.PopScene()
+	}
+
+	fonts := c.game.Fonts()
+	profile := c.game.profile
+	w, h := c.game.display.Width, c.game.display.Height
+	panel := image.Rect(w/4, h/4, 3*w/4, 3*h/4)
+	bodyH := lineHeight(fonts.Body)
+	titleH := lineHeight(fonts.Body) + 2*uiPad
+
+	c.ui.Begin()
+	c.ui.draw(func(screen *ebiten.Image) {
+		fillRect(screen, image.Rect(0, 0, w, h), color.RGBA{0, 0, 0, 0x60})
+	})
+	c.ui.Panel(panel)
+	c.ui.Label("crafting", panel.Min.X+uiPad*2, panel.Min.Y+uiPad, TextStyle{Face: fonts.Body, Color: uiFocusColor})
+
+	// recipes down the left
+	names := make([]string, len(sim.Recipes))
+	for i, r := range sim.Recipes {
+		names[i] = r.Name
+		if n := profile.Items[r.Item]; n > 0 {
+			names[i] = fmt.Sprintf("%s  x%d", r.Name, n)
+		}
+	}
+	listR := image.Rect(panel.Min.X+2*uiPad, panel.Min.Y+titleH, panel.Min.X+panel.Dx()/3, panel.Max.Y-2*uiPad)
+	c.ui.List(listR, names, &c.selected)
+
+	// the selected recipe's details on the right
+	r := sim.Recipes[c.selected]
+	x := listR.Max.X + 3*uiPad
+	detailW := panel.Max.X - 2*uiPad - x
+	y := listR.Min.Y
+	c.ui.Label(r.Name, x, y, TextStyle{Face: fonts.Body, Color: uiTextColor})
+	y += bodyH + uiPad
+	desc := TextStyle{Face: fonts.Small, Color: uiDimTextColor, MaxWidth: detailW}
+	c.ui.Label(r.Description, x, y, desc)
+	_, descH := MeasureText(r.Description, desc)
+	y += descH + 2*uiPad
+	for _, scrapType := range scrapTypes() {
+		need := r.Cost[scrapType]
+		if need == 0 {
+			continue
+		}
+		have := profile.Inventory[scrapType]
+		clr := uiTextColor
+		if have < need {
+			clr = color.RGBA{0xe0, 0x60, 0x50, 0xff}
+		}
+		c.ui.Label(fmt.Sprintf("%s  %d / %d", scrapLabels[scrapType], have, need), x, y, TextStyle{Face: fonts.Body, Color: clr})
+		y += bodyH
+	}
+
+	// craft and close along the bottom, with the craft's progress above
+	buttonH := lineHeight(fonts.Small) + 2*uiPad
+	buttonW := (detailW - uiPad) / 2
+	buttonY := panel.Max.Y - 2*uiPad - buttonH
+	if crafting != nil {
+		bar := image.Rect(x, buttonY-uiPad-12, x+detailW, buttonY-uiPad)
+		filled := bar
+		filled.Max.X = bar.Min.X + int(float64(bar.Dx())*progress)
+		label := fmt.Sprintf("putting together %s...", crafting.Name)
+		c.ui.draw(func(screen *ebiten.Image) {
+			fillRect(screen, bar, uiButtonColor)
+			fillRect(screen, filled, uiAccentColor)
+		})
+		c.ui.Label(label, x, bar.Min.Y-lineHeight(fonts.Small)-uiPad/2, TextStyle{Face: fonts.Small, Color: uiTextColor})
+	} else if c.clock.Now().Before(c.flashExpires) {
+		c.ui.Label(c.flash, x, buttonY-uiPad-lineHeight(fonts.Body), TextStyle{Face: fonts.Body, Color: uiFocusColor, Outline: color.Black})
+	}
+	if c.ui.Button(image.Rect(x, buttonY, x+buttonW, buttonY+buttonH), "craft", crafting == nil && profile.CanCraft(r) == nil) {
+		c.session.Issue(sim.Command{Craft: r.Item})
+	}
+	closing := c.ui.Button(image.Rect(x+buttonW+uiPad, buttonY, x+detailW, buttonY+buttonH), "close", crafting == nil)
+	c.ui.End()
+
+	// nothing under the overlay gets any input
+	input.Consume(sim.AllActions...)
+	input.ConsumeWheel()
+	if closing {
+		return c.game.PopScene()
+	}
+	return nil
+}
+
+func (c *craftingSceneImpl) Draw(screen *ebiten.Image) {
+	c.ui.Draw(screen)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type DisplayConfig struct {
+	// logical resolution everything is laid out in
+	Width, Height int
+	Zoom          float64
+	Fullscreen    bool
+	// only scale the logical screen by whole multiples so pixels stay square
+	IntegerScaling bool
+}
+
+func DefaultDisplayConfig() DisplayConfig {
+	return DisplayConfig{
+		Width:  1920,
+		Height: 1080,
+		Zoom:   1,
+	}
+}
+
+// presenter draws the logical screen into the window, letterboxed
+type presenter struct {
+	offscreen *ebiten.Image
+	scale     float64
+	offsetX   float64
+	offsetY   float64
+	outsideW  int
+	outsideH  int
+}
+
+func (p *presenter) layout(config DisplayConfig, outsideW, outsideH int) (int, int) {
+	deviceScale := ebiten.DeviceScaleFactor()
+	p.outsideW = int(float64(outsideW) * deviceScale)
+	p.outsideH = int(float64(outsideH) * deviceScale)
+	if p.offscreen == nil {
+		p.offscreen = ebiten.NewImage(config.Width, config.Height)
+	}
+	scale := math.Min(float64(p.outsideW)/float64(config.Width), float64(p.outsideH)/float64(config.Height))
+	if config.IntegerScaling && scale >= 1 {
+		scale = math.Floor(scale)
+	}
+	p.scale = scale
+	p.offsetX = math.Floor((float64(p.outsideW) - float64(config.Width)*scale) / 2)
+	p.offsetY = math.Floor((float64(p.outsideH) - float64(config.Height)*scale) / 2)
+	return p.outsideW, p.outsideH
+}
+
+func (p *presenter) present(screen *ebiten.Image) {
+	drawOpt := ebiten.DrawImageOptions{}
+	drawOpt.GeoM.Scale(p.scale, p.scale)
+	drawOpt.GeoM.Translate(p.offsetX, p.offsetY)
+	screen.DrawImage(p.offscreen, &drawOpt)
+}
+
+// toLogical converts a position from ebiten.CursorPosition into the logical
+// screen's coordinates
+func (p *presenter) toLogical(x, y int) (int, int) {
+	if p.scale == 0 {
+		return x, y
+	}
+	return int((float64(x) - p.offsetX) / p.scale), int((float64(y) - p.offsetY) / p.scale)
+}
@@ -2,65 +2,62 @@ package main
 
 import (
 	"fmt"
-	_ "image/png"
+	"image"
 	"math"
-	"math/rand"
 	"sort"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/val-is/ebitengine-magnetism/sim"
+	"github.com/val-is/ebitengine-magnetism/world"
 )
 
 type WorldObjectDrawable interface {
-	Draw(screen *ebiten.Image, cameraPos IsometricCoordinate)
-	ScreenPosition(cameraPos IsometricCoordinate) ScreenCoordinate
-	GetBottom(cameraPos IsometricCoordinate) ScreenCoordinate
+	Draw(screen *ebiten.Image, view *Viewport)
+	ScreenPosition(view *Viewport) ScreenCoordinate
+	GetBottom(view *Viewport) ScreenCoordinate
 }
 
-func DrawWorldObjects(screen *ebiten.Image, cameraPos IsometricCoordinate, objects []WorldObjectDrawable) {
+func DrawWorldObjects(screen *ebiten.Image, view *Viewport, objects []WorldObjectDrawable) {
 	// TODO sorting every frame sus
 	sort.Slice(objects, func(i, j int) bool {
-		aPos := objects[i].GetBottom(cameraPos)
-		bPos := objects[j].GetBottom(cameraPos)
+		aPos := objects[i].GetBottom(view)
+		bPos := objects[j].GetBottom(view)
 		return aPos.y < bPos.y
 	})
 
 	for _, object := range objects {
-		object.Draw(screen, cameraPos)
+		object.Draw(screen, view)
 	}
 }
 
 type WorldObject struct {
-	pos           IsometricCoordinate
+	pos           world.IsometricCoordinate
 	width, height float64
 }
 
-func (w *WorldObject) ScreenPosition(cameraPos IsometricCoordinate) ScreenCoordinate {
-	screenCoord := iso2Screen(IsometricCoordinate{
-		x: w.pos.x - cameraPos.x,
-		y: w.pos.y - cameraPos.y,
-		z: w.pos.z - cameraPos.z,
-	})
+func (w *WorldObject) ScreenPosition(view *Viewport) ScreenCoordinate {
+	screenCoord := view.WorldToScreen(w.pos)
 	return ScreenCoordinate{
-		screenCoord.x + 1920/2 - w.width/2,
-		screenCoord.y + 1080/2 - w.height/2,
+		screenCoord.x - w.width*view.zoom/2,
+		screenCoord.y - w.height*view.zoom/2,
 	}
 }
 
-func (w *WorldObject) GetBottom(cameraPos IsometricCoordinate) ScreenCoordinate {
-	scrPos := w.ScreenPosition(cameraPos)
+func (w *WorldObject) GetBottom(view *Viewport) ScreenCoordinate {
+	scrPos := w.ScreenPosition(view)
 	return ScreenCoordinate{
 		x: scrPos.x,
-		y: scrPos.y + w.height,
+		y: scrPos.y + w.height*view.zoom,
 	}
 }
 
-func (wo *WorldObject) DrawWithImg(screen *ebiten.Image, cameraPos IsometricCoordinate, img *ebiten.Image) {
+func (wo *WorldObject) DrawWithImg(screen *ebiten.Image, view *Viewport, img *ebiten.Image) {
 	w, h := img.Size()
-	screenCoord := wo.ScreenPosition(cameraPos)
+	screenCoord := wo.ScreenPosition(view)
 	drawOpt := ebiten.DrawImageOptions{}
 	drawOpt.GeoM.Reset()
-	drawOpt.GeoM.Scale(wo.width/float64(w), wo.height/float64(h))
+	drawOpt.GeoM.Scale(wo.width*view.zoom/float64(w), wo.height*view.zoom/float64(h))
 	drawOpt.GeoM.Translate(screenCoord.x, screenCoord.y)
 	screen.DrawImage(img, &drawOpt)
 }
@@ -68,354 +65,425 @@ func (wo *WorldObject) DrawWithImg(screen *ebiten.Image, cameraPos IsometricCoor
 type FacingDirection int
 
 const (
-	walkSpeed = 5.0 / 60.0
-
 	FACING_LEFT  = 0
 	FACING_RIGHT = 1
 
 	playerWidth  = tileWidth * 0.5
 	playerHeight = tileHeight * 0.5
 
-	playerCameraMaxDist   = 2
-	playerCameraMoveSpeed = walkSpeed
+	playerCameraMaxDist = 2
+
+	keyZoomRate = 0.1
+
+	// only the first scrap is panned to, after that the player knows to
+	// look and a pan would pull the camera away from them
+	scrapPanHold = 1500 * time.Millisecond
+	snapShake    = 0.6
 )
 
 type PlayerCharacter struct {
 	WorldObject
 	sprites []*ebiten.Image
 	facing  FacingDirection
-    bobber *FishingBobber
+	// the heading facing was last turned to
+	heading world.IsometricCoordinate
 }
 
-func (p *PlayerCharacter) Draw(screen *ebiten.Image, cameraPos IsometricCoordinate) {
+func (p *PlayerCharacter) Draw(screen *ebiten.Image, view *Viewport) {
 	img := p.sprites[p.facing]
-	p.DrawWithImg(screen, cameraPos, img)
+	p.DrawWithImg(screen, view, img)
 }
 
-var (
-    bobberSprite *ebiten.Image
-    bobPositions = []float64{-1, 0, 1, 0, 0, 1, 1, 2, 1, 0, 0, 0}
-    bobDelay = 500 * time.Millisecond
-)
-
 type FishingBobber struct {
-    WorldObject
-    bobPos int
-    active bool
-}
-
-func (f *FishingBobber) Draw(screen *ebiten.Image, cameraPos IsometricCoordinate) {
-    if f.active {
-        f.DrawWithImg(screen, cameraPos, bobberSprite)
-    }
+	WorldObject
+	sprite *ebiten.Image
+	active bool
 }
 
-func (f *FishingBobber) Bob() {
-    if f.bobPos >= len(bobPositions) {
-        f.bobPos = 0
-    }
-    f.WorldObject.pos = IsometricCoordinate{
-        x: f.WorldObject.pos.x,
-        y: f.WorldObject.pos.y,
-        z: waterLevel + bobPositions[f.bobPos] / 10,
-    }
-    f.bobPos++
+func (f *FishingBobber) Draw(screen *ebiten.Image, view *Viewport) {
+	if f.active {
+		f.DrawWithImg(screen, view, f.sprite)
+	}
 }
 
-type FoliageType int
-
-const (
-	foliageProb = 0.5
-
-	FOLIAGE_GRASS = 0
-	FOLIAGE_TREE  = 1
-)
-
-var (
-	foliageSprites map[FoliageType]*ebiten.Image
-)
-
 type Foliage struct {
 	WorldObject
-	foliageType FoliageType
+	foliageType world.FoliageType
+	sprite      *ebiten.Image
 }
 
-func (f *Foliage) Draw(screen *ebiten.Image, cameraPos IsometricCoordinate) {
-	img := foliageSprites[f.foliageType]
-	f.DrawWithImg(screen, cameraPos, img)
+func (f *Foliage) Draw(screen *ebiten.Image, view *Viewport) {
+	f.DrawWithImg(screen, view, f.sprite)
 }
 
-type ScrapType int
-
 const (
-	SCRAP_SCRAP = iota
-	SCRAP_WIRE
-	SCRAP_ELEC
-
-	scrapMinLife = 30 * time.Second
-	scrapMaxLife = 60 * time.Second
-
-	scrapSpawnPeriodMin = 15 * time.Second
-	scrapSpawnPeriodMax = 30 * time.Second
-)
-
-var (
-	// cumulative probs
-	scrapProbs = map[ScrapType]float64{
-		SCRAP_SCRAP: 0.5,
-		SCRAP_ELEC:  0.75,
-		SCRAP_WIRE:  1,
-	}
+	scrapSpritePx  = 32
+	scrapFadeTime  = 5 * time.Second
+	scrapPulseRate = 0.1 // radians per tick
 )
 
+// Scrap draws scrap floating on its water tile as a pulsing ripple, fading
+// out over its last scrapFadeTime
 type Scrap struct {
-	scrapType ScrapType
-	expires   time.Time
+	WorldObject
+	scrap  *sim.Scrap
+	clock  *sim.TickClock
+	sprite *ebiten.Image
 }
 
-type gameSceneImpl struct {
-	baseScene
-	tilemap     *Tilemap
-	cameraPos   IsometricCoordinate
-	mapSteps    [][]*Tile
-	currentStep int
-	mapRotation float64
+func (s *Scrap) Draw(screen *ebiten.Image, view *Viewport) {
+	remaining := s.scrap.Expires.Sub(s.clock.Now())
+	alpha := math.Max(0, math.Min(1, remaining.Seconds()/scrapFadeTime.Seconds()))
+	pulse := 1 + 0.15*math.Sin(float64(s.clock.Ticks())*scrapPulseRate)
 
-	drawing []WorldObjectDrawable
+	w, h := s.sprite.Size()
+	screenCoord := view.WorldToScreen(s.pos)
+	drawOpt := ebiten.DrawImageOptions{}
+	drawOpt.GeoM.Scale(s.width*view.zoom*pulse/float64(w), s.height*view.zoom*pulse/float64(h))
+	drawOpt.GeoM.Translate(
+		screenCoord.x-s.width*view.zoom*pulse/2,
+		screenCoord.y-s.height*view.zoom*pulse/2,
+	)
+	drawOpt.ColorM.Scale(1, 1, 1, alpha)
+	screen.DrawImage(s.sprite, &drawOpt)
+}
 
-	player  *PlayerCharacter
-	foliage []*Foliage
+// gameSceneImpl draws a sim.Session and feeds it input. Everything that
+// plays out in the world happens in the session; the camera, HUD and menus
+// are the scene's own.
+type gameSceneImpl struct {
+	game     *Game
+	session  *sim.Session
+	tilemap  *Tilemap
+	camera   *Camera
+	viewport *Viewport
 
-	scrapTiles map[IsometricCoordinate]*Scrap
-}
+	drawing []WorldObjectDrawable
 
-func (g *gameSceneImpl) _generateScrap() error {
-	roll := rand.Float64()
-	lowestProb := 1.1
-	var scrapType ScrapType
-	for potentialType, prob := range scrapProbs {
-		if prob < lowestProb && prob < roll {
-			lowestProb = prob
-			scrapType = potentialType
-		}
-	}
-	emptyTiles := make([]IsometricCoordinate, 0)
-	for coord, tile := range g.scrapTiles {
-		if tile == nil {
-			emptyTiles = append(emptyTiles, coord)
-		}
-	}
-	if len(emptyTiles) == 0 {
-		return nil
-	}
-	spawningCoord := emptyTiles[rand.Intn(len(emptyTiles))]
-	scrapLife := sampleTimeDuration(scrapMinLife, scrapMaxLife)
-	scrap := &Scrap{
-		scrapType: scrapType,
-		expires:   time.Now().Add(scrapLife),
-	}
-    fmt.Printf("generated scrap at %v of type %v\n", spawningCoord, scrapType)
-	g.scrapTiles[spawningCoord] = scrap
-    g.player.bobber.pos = spawningCoord
-	return nil
-}
+	player *PlayerCharacter
+	bobber *FishingBobber
 
-func (g *gameSceneImpl) generateScrapHook() error {
-	g._generateScrap()
-	nextTime := sampleTimeDuration(scrapSpawnPeriodMin, scrapSpawnPeriodMax)
-	g.actionQueue.Add(NewTimerAction(g.generateScrapHook, time.Now().Add(nextTime)))
-	return nil
-}
+	scrap         map[world.IsometricCoordinate]*Scrap
+	scrapSprite   *ebiten.Image
+	pannedToScrap bool
+	hud           *HUD
+	ui            *UI
 
-func (g *gameSceneImpl) bobHook() error {
-    g.player.bobber.Bob()
-    nextTime := time.Now().Add(bobDelay)
-    g.actionQueue.Add(NewTimerAction(g.bobHook, nextTime))
-    return nil
+	// dropped on Stop
+	subscriptions []func()
 }
 
-func (g *gameSceneImpl) _updateMapSteps() error {
-	g.tilemap.tiles = generateMapFromTiles(g.mapSteps[g.currentStep])
-	g.currentStep++
-	if g.currentStep < len(g.mapSteps) {
-		g.actionQueue.Add(NewTimerAction(g._updateMapSteps, time.Now().Add(20*time.Millisecond)))
+func (g *gameSceneImpl) removeDrawing(object WorldObjectDrawable) {
+	for i, other := range g.drawing {
+		if other == object {
+			g.drawing = append(g.drawing[:i], g.drawing[i+1:]...)
+			return
+		}
 	}
-	return nil
 }
 
 func (g *gameSceneImpl) Start() error {
-	var centerTile IsometricCoordinate
-	g.tilemap.tiles, centerTile, g.mapSteps = generateMap()
-
-	g.scrapTiles = make(map[IsometricCoordinate]*Scrap)
-tileSearchLoop:
-	for _, tile := range g.tilemap.tiles {
-		if tile.tileType == TILE_WATER {
-			for _, adj := range getAdjIsometric(tile.coord) {
-				for _, tileAt := range g.tilemap.GetTilesAt(adj) {
-					if tileAt.tileType != TILE_WATER {
-						g.scrapTiles[tile.coord] = nil
-						continue tileSearchLoop
-					}
-				}
+	g.scrap = make(map[world.IsometricCoordinate]*Scrap)
+	g.scrapSprite = newRippleSprite(scrapSpritePx)
+	g.hud = NewHUD(g.session.Clock(), g.game.profile, g.game.Fonts())
+	g.ui = NewUI(g.game.input, g.game.Fonts())
+	events := g.game.events
+	g.subscriptions = append(g.subscriptions, g.hud.Subscribe(events)...)
+	g.subscriptions = append(g.subscriptions,
+		sim.Subscribe(events, func(e sim.TilesChanged) {
+			g.tilemap.SetTiles(e.Tiles)
+		}),
+		sim.Subscribe(events, func(e sim.ScrapSpawned) {
+			scrap := &Scrap{
+				WorldObject: WorldObject{
+					pos:    e.Coord,
+					width:  tileWidth * 0.5,
+					height: tileHeight * 0.25,
+				},
+				scrap:  g.session.ScrapAt(e.Coord),
+				clock:  g.session.Clock(),
+				sprite: g.scrapSprite,
 			}
-		}
+			g.scrap[e.Coord] = scrap
+			g.drawing = append(g.drawing, scrap)
+			if !g.pannedToScrap {
+				g.pannedToScrap = true
+				g.camera.PanTo(e.Coord, scrapPanHold)
+			}
+		}),
+		sim.Subscribe(events, func(e sim.ScrapDespawned) {
+			g.removeDrawing(g.scrap[e.Coord])
+			delete(g.scrap, e.Coord)
+		}),
+		sim.Subscribe(events, func(e sim.ReelFinished) {
+			switch e.Result {
+			case sim.ReelCaught:
+				g.game.SaveProfile()
+			case sim.ReelSnapped:
+				g.camera.Shake(snapShake)
+			}
+		}),
+		sim.Subscribe(events, func(sim.UpgradePurchased) {
+			g.game.SaveProfile()
+		}),
+		sim.Subscribe(events, func(sim.ItemCrafted) {
+			g.game.SaveProfile()
+		}),
+	)
+	if rec := g.game.recording; rec != nil {
+		g.session.Record(rec)
+		g.game.recording = nil
+		g.game.recordedSession = g.session
 	}
-
-	centerTileScreen := iso2Screen(centerTile)
-	g.player.pos = screen2Iso(centerTileScreen)
 	g.drawing = make([]WorldObjectDrawable, 0)
+	if err := g.session.Start(); err != nil {
+		return err
+	}
 
-	playerSpritesheet, err := LoadTiledSpritemap("./resources/Tiny_Tales_Wild_Beasts_NPC_1.0/RPG_Maker/32/$Fox_1.png", 32, 32, 3, 4, 0, 0)
+	g.player.pos = g.session.Player().Pos
+	g.camera.SetBounds(g.session.Map())
+	g.camera.SnapTo(g.player.pos)
+
+	playerSpritesheet, err := g.game.assets.Spritemap("Tiny_Tales_Wild_Beasts_NPC_1.0/RPG_Maker/32/$Fox_1.png", 32, 32, 3, 4, 0, 0)
 	if err != nil {
 		return err
 	}
 	g.player.sprites = playerSpritesheet
 	g.drawing = append(g.drawing, g.player)
-    bobberSprite = playerSpritesheet[1]
-    g.drawing = append(g.drawing, g.player.bobber)
+	g.bobber.sprite = playerSpritesheet[1]
+	g.drawing = append(g.drawing, g.bobber)
 
-	foliageSpritesheetRaw, err := LoadTiledSpritemap("./resources/48x48 & 16x32 Trees/16x32 trees.png", 16, 32, 4, 2, 0, 0)
+	foliageSpritesheetRaw, err := g.game.assets.Spritemap("48x48 & 16x32 Trees/16x32 trees.png", 16, 32, 4, 2, 0, 0)
 	if err != nil {
 		return err
 	}
-	foliageSprites = map[FoliageType]*ebiten.Image{
-		FOLIAGE_GRASS: foliageSpritesheetRaw[7],
-		FOLIAGE_TREE:  foliageSpritesheetRaw[1],
+	foliageSprites := map[world.FoliageType]*ebiten.Image{
+		world.FOLIAGE_GRASS: foliageSpritesheetRaw[7],
+		world.FOLIAGE_TREE:  foliageSpritesheetRaw[1],
 	}
-	g.foliage = make([]*Foliage, 0)
-	for _, tile := range g.tilemap.tiles {
-		if tile.tileType == TILE_LAND && rand.Float64() < foliageProb {
-			newFoliage := &Foliage{
-				WorldObject: WorldObject{
-					pos: IsometricCoordinate{
-						tile.coord.x,
-						tile.coord.y,
-						tile.coord.z + 1.5,
-					},
-					width:  0.5 * tileWidth,
-					height: tileHeight,
-				},
-				foliageType: FoliageType(rand.Intn(2)),
-			}
-			g.foliage = append(g.foliage, newFoliage)
-			g.drawing = append(g.drawing, newFoliage)
-		}
+	for _, foliage := range g.session.Foliage() {
+		g.drawing = append(g.drawing, &Foliage{
+			WorldObject: WorldObject{
+				pos:    foliage.Pos,
+				width:  0.5 * tileWidth,
+				height: tileHeight,
+			},
+			foliageType: foliage.Type,
+			sprite:      foliageSprites[foliage.Type],
+		})
 	}
+	return nil
+}
 
-	g.actionQueue.Add(func() (bool, error) {
-		// move player
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			mouseX, mouseY := ebiten.CursorPosition()
-			playerScreenPos := g.player.ScreenPosition(g.cameraPos)
-			mouseXCentered := float64(mouseX) - playerScreenPos.x
-			mouseYCentered := float64(mouseY) - playerScreenPos.y
-			clickVec := screen2Iso(ScreenCoordinate{
-				x: mouseXCentered,
-				y: mouseYCentered,
-			})
-			clickVecDist := math.Hypot(clickVec.x, clickVec.y)
-			moveVec := IsometricCoordinate{
-				x: clickVec.x / clickVecDist * walkSpeed,
-				y: clickVec.y / clickVecDist * walkSpeed,
-			}
-			newPlayerPos := IsometricCoordinate{
-				x: g.player.pos.x + moveVec.x,
-				y: g.player.pos.y + moveVec.y,
-			}
-			if mouseXCentered < 0 {
-				g.player.facing = FACING_LEFT
-			} else {
-				g.player.facing = FACING_RIGHT
-			}
-			tilesAtPos := g.tilemap.GetTilesAt(newPlayerPos)
-			for _, tile := range tilesAtPos {
-				if tile.walkable {
-					g.player.pos = newPlayerPos
-					g.player.pos.z = tile.coord.z + 0.5
-					break
-				}
-			}
+// controls is what the session gets of this tick's input once the UI has
+// had it, with movement turned from the screen onto the ground
+func (g *gameSceneImpl) controls() sim.Controls {
+	input := g.game.input
+	c := sim.Controls{}
+	for _, action := range sim.AllActions {
+		if input.Pressed(action) {
+			c.Held.Set(action)
+		}
+		if input.JustPressed(action) {
+			c.Pressed.Set(action)
 		}
+	}
 
-		// lock player to camera view
-		playerScreenPos := g.player.ScreenPosition(g.cameraPos)
-		playerCamDistVec := screen2Iso(ScreenCoordinate{
-			x: playerScreenPos.x - 1920/2,
-			y: playerScreenPos.y - 1080/2,
+	// toward the cursor while it's held, otherwise by the move keys/stick
+	// where up on screen is up the isometric grid
+	screenDirX, screenDirY := input.MoveVector()
+	speed := math.Hypot(screenDirX, screenDirY)
+	if c.Held.Has(sim.ActionMoveToCursor) {
+		mouseX, mouseY := input.Cursor()
+		playerScreenPos := g.player.ScreenPosition(g.viewport)
+		screenDirX = float64(mouseX) - playerScreenPos.x
+		screenDirY = float64(mouseY) - playerScreenPos.y
+		speed = 1
+	}
+	if screenDirX != 0 || screenDirY != 0 {
+		dirVec := screen2Iso(ScreenCoordinate{
+			x: screenDirX,
+			y: screenDirY,
 		})
-		playerCamDist := math.Hypot(playerCamDistVec.x, playerCamDistVec.y)
-		if playerCamDist > playerCameraMaxDist {
-			g.cameraPos = IsometricCoordinate{
-				x: g.cameraPos.x + playerCamDistVec.x/playerCamDist*playerCameraMoveSpeed,
-				y: g.cameraPos.y + playerCamDistVec.y/playerCamDist*playerCameraMoveSpeed,
-				z: g.cameraPos.z,
-			}
-		}
+		dirVecDist := math.Hypot(dirVec.X, dirVec.Y)
+		c.MoveX = dirVec.X / dirVecDist * speed
+		c.MoveY = dirVec.Y / dirVecDist * speed
+	}
+	return c
+}
 
-		return false, nil
-	})
-    
-    g.bobHook()
-	g.generateScrapHook()
-	// g.actionQueue.Add(NewTimerAction(g._updateMapSteps, time.Now().Add(1500 * time.Millisecond)))
-	return nil
+// follow moves the player and bobber drawables to where the session has
+// them
+func (g *gameSceneImpl) follow() {
+	player := g.session.Player()
+	g.player.pos = player.Pos
+	if player.Heading != g.player.heading {
+		g.player.heading = player.Heading
+		if iso2Screen(player.Heading).x < 0 {
+			g.player.facing = FACING_LEFT
+		} else {
+			g.player.facing = FACING_RIGHT
+		}
+	}
+	bobber := g.session.Bobber()
+	g.bobber.pos = bobber.Pos
+	g.bobber.active = bobber.Active
 }
 
 func (g *gameSceneImpl) Stop() error {
+	g.session.Stop()
+	g.game.SaveProfile()
+	for _, unsubscribe := range g.subscriptions {
+		unsubscribe()
+	}
+	g.subscriptions = nil
 	return nil
 }
 
 func (g *gameSceneImpl) Update() error {
-	return g.baseScene.Update()
+	input := g.game.input
+	if input.JustPressed(sim.ActionMenu) {
+		pause, err := NewPauseScene(g.game)
+		if err != nil {
+			return err
+		}
+		return g.game.PushScene(pause)
+	}
+	g.tilemap.Update()
+	// the UI goes first so it can take clicks before the player walks off
+	// after them
+	if g.session.IntroDone() {
+		g.ui.Begin()
+		g.upgradePanel()
+		g.ui.End()
+	}
+	if err := g.session.Step(g.controls()); err != nil {
+		return err
+	}
+	g.follow()
+	if !g.session.IntroDone() {
+		g.camera.Update()
+		return nil
+	}
+	g.hud.Update()
+	g.hud.UpdateSignal(g.session.NearestScrap())
+
+	if input.JustPressed(sim.ActionCraft) && g.session.Minigame() == nil {
+		crafting, err := NewCraftingScene(g.game, g.session)
+		if err != nil {
+			return err
+		}
+		if err := g.game.PushScene(crafting); err != nil {
+			return err
+		}
+	}
+
+	zoom := input.Wheel()
+	if input.Pressed(sim.ActionZoomIn) {
+		zoom += keyZoomRate
+	}
+	if input.Pressed(sim.ActionZoomOut) {
+		zoom -= keyZoomRate
+	}
+	if zoom != 0 {
+		g.camera.ZoomBy(zoom)
+	}
+	g.camera.Follow(g.player.pos)
+	g.camera.Update()
+	return nil
+}
+
+// upgradePanel is a button per upgrade down the right of the screen, below
+// the HUD's objective
+func (g *gameSceneImpl) upgradePanel() {
+	fonts := g.game.Fonts()
+	w, h := g.game.display.Width, g.game.display.Height
+	margin := h / 40
+	buttonW := w / 6
+	buttonH := 2*lineHeight(fonts.Small) + uiPad
+	top := margin + lineHeight(fonts.Small) + lineHeight(fonts.Body) + 6 + 2*uiPad + margin
+	panel := image.Rect(w-margin-buttonW-uiPad, top, w-margin+uiPad, top+lineHeight(fonts.Small)+uiPad+len(sim.Upgrades)*(buttonH+uiPad)+uiPad)
+	g.ui.Panel(panel)
+	g.ui.Label("upgrades", panel.Min.X+uiPad, panel.Min.Y+uiPad, TextStyle{Face: fonts.Small, Color: uiDimTextColor})
+	profile := g.game.profile
+	for i, u := range sim.UpgradeActions {
+		upgrade := sim.FindUpgrade(u.ID)
+		level := profile.Upgrades[u.ID]
+		label := fmt.Sprintf("%d  %s  %d/%d\nmaxed out", i+1, upgrade.Name, level, upgrade.MaxLevel)
+		if level < upgrade.MaxLevel {
+			label = fmt.Sprintf("%d  %s  %d/%d\n%s", i+1, upgrade.Name, level, upgrade.MaxLevel, sim.FormatScrap(upgrade.Cost(level+1)))
+		}
+		y := panel.Min.Y + uiPad + lineHeight(fonts.Small) + uiPad + i*(buttonH+uiPad)
+		r := image.Rect(panel.Min.X+uiPad, y, panel.Max.X-uiPad, y+buttonH)
+		if g.ui.Button(r, label, profile.CanBuy(u.ID) == nil) {
+			g.session.Issue(sim.Command{Buy: u.ID})
+		}
+	}
 }
 
 func (g *gameSceneImpl) Draw(screen *ebiten.Image) {
-	g.tilemap.Draw(screen, g.cameraPos)
-	DrawWorldObjects(screen, g.cameraPos, g.drawing)
-	// g.player.Draw(screen, g.cameraPos)
+	g.tilemap.Draw(screen, g.viewport)
+	introDone := g.session.IntroDone()
+	if introDone {
+		DrawWorldObjects(screen, g.viewport, g.drawing)
+	}
+	if minigame := g.session.Minigame(); minigame != nil {
+		pos := g.player.ScreenPosition(g.viewport)
+		drawReel(screen, minigame, pos.x+g.player.width*g.viewport.zoom/2, pos.y-3*reelBarHeight)
+	}
+	if introDone {
+		g.hud.Draw(screen)
+		g.ui.Draw(screen)
+	}
+	// g.player.Draw(screen, g.viewport)
 	// for _, foliage := range g.foliage {
-	//     foliage.Draw(screen, g.cameraPos)
+	//     foliage.Draw(screen, g.viewport)
 	// }
-    // for tile, scrap := range g.scrapTiles {
-    //     if scrap != nil {
-    //         DrawWorldObjects(screen, g.cameraPos, []WorldObjectDrawable{
-    //             &Foliage{
-    //                 WorldObject: WorldObject{
-    //                     pos: tile,
-    //                     width: tileWidth*0.5,
-    //                     height: tileHeight*0.5,
-    //                 },
-    //                 foliageType: 0,
-    //             },
-    //         })
-    //     }
-    // }
+}
+
+// loadBiomes reads the biome table from assets
+func loadBiomes(assets *AssetManager) (*world.BiomeTable, error) {
+	raw, err := assets.ReadFile("biomes.json")
+	if err != nil {
+		return nil, err
+	}
+	return world.ParseBiomes(raw, "biomes.json")
 }
 
 func NewGameScene(game *Game) (Scene, error) {
-	tilemap, err := NewTilemap("./resources/isometric-sandbox-32x32/isometric-sandbox-sheet.png", idxTileMap)
+	tilemap, err := game.assets.Tilemap("tiles.json")
 	if err != nil {
 		return nil, err
 	}
+	biomes, err := loadBiomes(game.assets)
+	if err != nil {
+		return nil, err
+	}
+	if err := biomes.CheckTiles(tilemap.HasTile); err != nil {
+		return nil, fmt.Errorf("biomes.json: %w", err)
+	}
+	camera := NewCamera(NewViewport(game.display.Width, game.display.Height, game.display.Zoom))
 	return &gameSceneImpl{
-		baseScene: NewBaseScene(game),
-		tilemap:   tilemap,
-		cameraPos: IsometricCoordinate{},
+		game:     game,
+		session:  sim.NewSession(game.seed, game.generator, biomes, game.profile, game.events),
+		tilemap:  tilemap,
+		camera:   camera,
+		viewport: camera.Viewport(),
 		player: &PlayerCharacter{
 			WorldObject: WorldObject{
-				pos:    IsometricCoordinate{},
+				pos:    world.IsometricCoordinate{},
 				width:  playerWidth,
 				height: playerHeight,
 			},
-            bobber: &FishingBobber{
-                WorldObject: WorldObject{
-                    pos: IsometricCoordinate{},
-                    width: playerWidth/2,
-                    height: playerHeight/2,
-                },
-                active: true,
-            },
+			heading: world.IsometricCoordinate{X: 1},
+		},
+		bobber: &FishingBobber{
+			WorldObject: WorldObject{
+				pos:    world.IsometricCoordinate{},
+				width:  playerWidth / 2,
+				height: playerHeight / 2,
+			},
+			active: false,
 		},
 	}, nil
 }
@@ -1,19 +1,44 @@
 package main
 
 import (
+	"fmt"
 	"image"
+	"image/color"
+	_ "image/png"
+	"io/fs"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
 // <tileset version="1.5" tiledversion="1.6.0" name="isometric-sandbox-sheet" tilewidth="32" tileheight="32" tilecount="2" columns="2" objectalignment="topleft">
 
-func LoadTiledSpritemap(filepath string, imW, imH, tilesW, tilesH, paddingW, paddingH int) ([]*ebiten.Image, error) {
-    spritemapRaw, _, err := ebitenutil.NewImageFromFile(filepath)
+func decodeImage(fsys fs.FS, filepath string) (image.Image, error) {
+    f, err := fsys.Open(filepath)
     if err != nil {
         return nil, err
     }
+    defer f.Close()
+    img, _, err := image.Decode(f)
+    if err != nil {
+        return nil, fmt.Errorf("decoding %s: %w", filepath, err)
+    }
+    return img, nil
+}
+
+// LoadImage and LoadTiledSpritemap load straight from fsys with no caching
+// or hot reload, for callers that don't have an AssetManager
+func LoadImage(fsys fs.FS, filepath string) (*ebiten.Image, error) {
+    return NewAssetManager(fsys, "filesystem").Image(filepath)
+}
+
+func LoadTiledSpritemap(fsys fs.FS, filepath string, imW, imH, tilesW, tilesH, paddingW, paddingH int) ([]*ebiten.Image, error) {
+    return NewAssetManager(fsys, "filesystem").Spritemap(filepath, imW, imH, tilesW, tilesH, paddingW, paddingH)
+}
+
+// sliceSpritemap cuts a sheet into sub-images that share the sheet's pixels,
+// so reloading the sheet updates every sprite cut from it
+func sliceSpritemap(spritemapRaw *ebiten.Image, imW, imH, tilesW, tilesH, paddingW, paddingH int) []*ebiten.Image {
     tileImages := make([]*ebiten.Image, 0)
     for x := 0; x<tilesW; x++ {
         for y := 0; y<tilesH; y++ {
@@ -27,9 +52,27 @@ func LoadTiledSpritemap(filepath string, imW, imH, tilesW, tilesH, paddingW, pad
                     Y: (y+1)*imH + y*paddingH,
                 },
             })
-            ebiImg := ebiten.NewImageFromImage(subImg)
-            tileImages = append(tileImages, ebiImg)
+            tileImages = append(tileImages, subImg.(*ebiten.Image))
+        }
+    }
+    return tileImages
+}
+
+// newRippleSprite draws a flattened double ring with a glint in the middle,
+// the marker for something floating on the water
+func newRippleSprite(size int) *ebiten.Image {
+    img := image.NewRGBA(image.Rect(0, 0, size, size/2))
+    cx, cy := float64(size)/2, float64(size)/4
+    for x := 0; x < size; x++ {
+        for y := 0; y < size/2; y++ {
+            dx, dy := (float64(x)+0.5-cx)/cx, (float64(y)+0.5-cy)/cy
+            d := math.Hypot(dx, dy)
+            a := math.Max(0, 1-math.Abs(d-0.8)/0.12) + 0.6*math.Max(0, 1-math.Abs(d-0.45)/0.1)
+            a = math.Max(a, math.Max(0, 1-d/0.15))
+            a = math.Min(1, a)
+            v := uint8(255 * a)
+            img.SetRGBA(x, y, color.RGBA{v, v, v, v})
         }
     }
-    return tileImages, nil
+    return ebiten.NewImageFromImage(img)
 }
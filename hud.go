@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/val-is/ebitengine-magnetism/sim"
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+const (
+	// toasts stack below each other and drop off after toastTime
+	toastTime = 2500 * time.Millisecond
+	maxToasts = 4
+
+	signalBars = 10
+)
+
+var scrapLabels = map[world.ScrapType]string{
+	world.SCRAP_SCRAP: "Scrap",
+	world.SCRAP_WIRE:  "Wire",
+	world.SCRAP_ELEC:  "Electronics",
+}
+
+// boatCost is the scrap it takes to build a boat off the island
+var boatCost = map[world.ScrapType]int{
+	world.SCRAP_SCRAP: 20,
+	world.SCRAP_WIRE:  10,
+	world.SCRAP_ELEC:  8,
+}
+
+// boatProgress is how much of boatCost inv covers, 0 to 1
+func boatProgress(inv sim.Inventory) float64 {
+	have, need := 0, 0
+	for scrapType, n := range boatCost {
+		have += int(math.Min(float64(inv[scrapType]), float64(n)))
+		need += n
+	}
+	return float64(have) / float64(need)
+}
+
+// scrapTypes is every scrap type in ScrapType order
+func scrapTypes() []world.ScrapType {
+	types := make([]world.ScrapType, 0, len(world.ScrapTypeNames))
+	for _, scrapType := range world.ScrapTypeNames {
+		types = append(types, scrapType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+type hudToast struct {
+	text    string
+	expires time.Time
+}
+
+// HUD draws over the world: inventory top left, boat progress top right,
+// the magnet's signal bottom left and toasts top centre. Everything is placed
+// relative to the screen it's drawn on.
+type HUD struct {
+	clock   *sim.TickClock
+	profile *sim.Profile
+	fonts   *Fonts
+	toasts  []hudToast
+	// 0 to 1, how strongly the magnet picks up the nearest scrap
+	Signal float64
+}
+
+func NewHUD(clock *sim.TickClock, profile *sim.Profile, fonts *Fonts) *HUD {
+	return &HUD{clock: clock, profile: profile, fonts: fonts}
+}
+
+// Subscribe hooks the HUD's toasts up to bus, returning the unsubscribe funcs
+func (h *HUD) Subscribe(bus *sim.EventBus) []func() {
+	return []func(){
+		sim.Subscribe(bus, func(e sim.ReelFinished) {
+			switch e.Result {
+			case sim.ReelCaught:
+				h.Toast(fmt.Sprintf("Caught: %s", scrapLabels[e.Type]))
+			case sim.ReelSnapped:
+				h.Toast("The line snapped")
+			case sim.ReelSlipped:
+				h.Toast(fmt.Sprintf("%s slipped off the magnet", scrapLabels[e.Type]))
+			}
+		}),
+		sim.Subscribe(bus, func(e sim.ItemCrafted) {
+			for _, r := range sim.Recipes {
+				if r.Item == e.Item {
+					h.Toast(fmt.Sprintf("Crafted: %s", r.Name))
+				}
+			}
+		}),
+		sim.Subscribe(bus, func(e sim.UpgradePurchased) {
+			h.Toast(fmt.Sprintf("Upgraded %s to level %d", sim.FindUpgrade(e.ID).Name, e.Level))
+		}),
+		sim.Subscribe(bus, func(e sim.UpgradeRefused) {
+			h.Toast(e.Reason.Error())
+		}),
+		sim.Subscribe(bus, func(sim.CastMissed) {
+			h.Toast("Can't cast there, aim for the water")
+		}),
+	}
+}
+
+func (h *HUD) Toast(text string) {
+	h.toasts = append(h.toasts, hudToast{text: text, expires: h.clock.Now().Add(toastTime)})
+	if len(h.toasts) > maxToasts {
+		h.toasts = h.toasts[len(h.toasts)-maxToasts:]
+	}
+}
+
+func (h *HUD) Update() {
+	now := h.clock.Now()
+	for len(h.toasts) > 0 && !now.Before(h.toasts[0].expires) {
+		h.toasts = h.toasts[1:]
+	}
+}
+
+// UpdateSignal sets Signal from the distance between the magnet and the
+// nearest scrap in tiles, full on top of it and halving every
+// DetectionRadius tiles
+func (h *HUD) UpdateSignal(distance float64, found bool) {
+	if !found {
+		h.Signal = 0
+		return
+	}
+	h.Signal = math.Exp2(-distance / h.profile.Stats().DetectionRadius)
+}
+
+func (h *HUD) Draw(screen *ebiten.Image) {
+	w, ht := screen.Size()
+	margin := ht / 40
+	pad := 8
+	panel := color.RGBA{0x10, 0x10, 0x10, 0xa0}
+	label := TextStyle{Face: h.fonts.Small, Color: color.RGBA{0xc0, 0xc0, 0xc0, 0xff}}
+	body := TextStyle{Face: h.fonts.Body, Shadow: color.Black}
+	lineH := lineHeight(h.fonts.Body)
+
+	// inventory
+	types := scrapTypes()
+	_, labelH := MeasureText("inventory", label)
+	invW := 0
+	for _, scrapType := range types {
+		if lw, _ := MeasureText(scrapLabels[scrapType]+" 000", body); lw > invW {
+			invW = lw
+		}
+	}
+	ebitenutil.DrawRect(screen, float64(margin), float64(margin), float64(invW+2*pad), float64(labelH+len(types)*lineH+2*pad), panel)
+	DrawText(screen, "inventory", margin+pad, margin+pad, label)
+	for i, scrapType := range types {
+		y := margin + pad + labelH + i*lineH
+		DrawText(screen, scrapLabels[scrapType], margin+pad, y, body)
+		count := body
+		count.Align = AlignRight
+		DrawText(screen, fmt.Sprint(h.profile.Inventory[scrapType]), margin+pad+invW, y, count)
+	}
+
+	// boat progress
+	barW := w / 6
+	boatX := w - margin - barW
+	progress := boatProgress(h.profile.Inventory)
+	ebitenutil.DrawRect(screen, float64(boatX-pad), float64(margin), float64(barW+2*pad), float64(labelH+lineH+2*pad), panel)
+	DrawText(screen, "objective", boatX, margin+pad, label)
+	DrawText(screen, fmt.Sprintf("build a boat  %d%%", int(progress*100)), boatX, margin+pad+labelH, body)
+	barY := float64(margin + pad + labelH + lineH)
+	ebitenutil.DrawRect(screen, float64(boatX), barY, float64(barW), 6, color.RGBA{0x30, 0x30, 0x30, 0xff})
+	ebitenutil.DrawRect(screen, float64(boatX), barY, float64(barW)*progress, 6, color.RGBA{0xc0, 0x90, 0x40, 0xff})
+
+	// signal meter, one bar lit per tenth of signal
+	const barSize = 10
+	signalY := float64(ht - margin - pad - barSize*2)
+	ebitenutil.DrawRect(screen, float64(margin), signalY-float64(labelH+pad), signalBars*(barSize+2)+float64(2*pad), float64(2*barSize+labelH+2*pad), panel)
+	DrawText(screen, "signal", margin+pad, int(signalY)-labelH, label)
+	lit := int(math.Round(h.Signal * signalBars))
+	for i := 0; i < signalBars; i++ {
+		c := color.RGBA{0x30, 0x30, 0x30, 0xff}
+		if i < lit {
+			c = color.RGBA{0x40, 0xa0 + uint8(i*0x0a), 0xe0, 0xff}
+		}
+		barH := barSize * 2 * float64(i+1) / signalBars
+		ebitenutil.DrawRect(screen, float64(margin+pad+i*(barSize+2)), signalY+barSize*2-barH, barSize, barH, c)
+	}
+
+	// toasts
+	toastStyle := TextStyle{Face: h.fonts.Body, Align: AlignCenter, Outline: color.Black}
+	y := margin
+	for _, toast := range h.toasts {
+		tw, th := MeasureText(toast.text, toastStyle)
+		ebitenutil.DrawRect(screen, float64(w/2-tw/2-pad), float64(y), float64(tw+2*pad), float64(th+pad), panel)
+		DrawText(screen, toast.text, w/2, y+pad/2, toastStyle)
+		y += th + 2*pad
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+const gamepadDeadzone = 0.25
+
+func defaultBindings() map[sim.InputAction][]string {
+	return map[sim.InputAction][]string{
+		sim.ActionMoveUp:       {"key:W", "key:ArrowUp", "pad:up", "axis:lefty-"},
+		sim.ActionMoveDown:     {"key:S", "key:ArrowDown", "pad:down", "axis:lefty+"},
+		sim.ActionMoveLeft:     {"key:A", "key:ArrowLeft", "pad:left", "axis:leftx-"},
+		sim.ActionMoveRight:    {"key:D", "key:ArrowRight", "pad:right", "axis:leftx+"},
+		sim.ActionMoveToCursor: {"mouse:left"},
+		sim.ActionCast:         {"key:Space", "mouse:right", "pad:a"},
+		sim.ActionReel:         {"key:R", "mouse:middle", "pad:b"},
+		sim.ActionInteract:     {"key:E", "key:Enter", "pad:x"},
+		sim.ActionMenu:         {"key:Escape", "pad:start"},
+		sim.ActionZoomIn:       {"key:Equal", "pad:rb"},
+		sim.ActionZoomOut:      {"key:Minus", "pad:lb"},
+		sim.ActionBuyMagnet:    {"key:Digit1"},
+		sim.ActionBuyCastRange: {"key:Digit2"},
+		sim.ActionBuyReelSpeed: {"key:Digit3"},
+		sim.ActionBuyDetection: {"key:Digit4"},
+		sim.ActionBuyWalkSpeed: {"key:Digit5"},
+		sim.ActionClick:        {"mouse:left"},
+		sim.ActionCraft:        {"key:C", "pad:y"},
+	}
+}
+
+var (
+	keysByName = func() map[string]ebiten.Key {
+		keys := make(map[string]ebiten.Key)
+		for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+			keys[strings.ToLower(k.String())] = k
+		}
+		return keys
+	}()
+
+	mouseButtonsByName = map[string]ebiten.MouseButton{
+		"left":   ebiten.MouseButtonLeft,
+		"right":  ebiten.MouseButtonRight,
+		"middle": ebiten.MouseButtonMiddle,
+	}
+
+	padButtonsByName = map[string]ebiten.StandardGamepadButton{
+		"a":      ebiten.StandardGamepadButtonRightBottom,
+		"b":      ebiten.StandardGamepadButtonRightRight,
+		"x":      ebiten.StandardGamepadButtonRightLeft,
+		"y":      ebiten.StandardGamepadButtonRightTop,
+		"lb":     ebiten.StandardGamepadButtonFrontTopLeft,
+		"rb":     ebiten.StandardGamepadButtonFrontTopRight,
+		"lt":     ebiten.StandardGamepadButtonFrontBottomLeft,
+		"rt":     ebiten.StandardGamepadButtonFrontBottomRight,
+		"back":   ebiten.StandardGamepadButtonCenterLeft,
+		"start":  ebiten.StandardGamepadButtonCenterRight,
+		"ls":     ebiten.StandardGamepadButtonLeftStick,
+		"rs":     ebiten.StandardGamepadButtonRightStick,
+		"up":     ebiten.StandardGamepadButtonLeftTop,
+		"down":   ebiten.StandardGamepadButtonLeftBottom,
+		"left":   ebiten.StandardGamepadButtonLeftLeft,
+		"right":  ebiten.StandardGamepadButtonLeftRight,
+		"center": ebiten.StandardGamepadButtonCenterCenter,
+	}
+
+	padAxesByName = map[string]ebiten.StandardGamepadAxis{
+		"leftx":  ebiten.StandardGamepadAxisLeftStickHorizontal,
+		"lefty":  ebiten.StandardGamepadAxisLeftStickVertical,
+		"rightx": ebiten.StandardGamepadAxisRightStickHorizontal,
+		"righty": ebiten.StandardGamepadAxisRightStickVertical,
+	}
+)
+
+// Binding is one physical control that can trigger an action. Value returns
+// how far it's pushed, 0 to 1, so sticks can drive movement analog.
+type Binding interface {
+	Value(gamepads []ebiten.GamepadID) float64
+}
+
+type keyBinding ebiten.Key
+
+func (k keyBinding) Value(gamepads []ebiten.GamepadID) float64 {
+	if ebiten.IsKeyPressed(ebiten.Key(k)) {
+		return 1
+	}
+	return 0
+}
+
+type mouseBinding ebiten.MouseButton
+
+func (m mouseBinding) Value(gamepads []ebiten.GamepadID) float64 {
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButton(m)) {
+		return 1
+	}
+	return 0
+}
+
+type padButtonBinding ebiten.StandardGamepadButton
+
+func (p padButtonBinding) Value(gamepads []ebiten.GamepadID) float64 {
+	for _, id := range gamepads {
+		if ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButton(p)) {
+			return 1
+		}
+	}
+	return 0
+}
+
+type padAxisBinding struct {
+	axis ebiten.StandardGamepadAxis
+	sign float64
+}
+
+func (p padAxisBinding) Value(gamepads []ebiten.GamepadID) float64 {
+	best := 0.0
+	for _, id := range gamepads {
+		if v := ebiten.StandardGamepadAxisValue(id, p.axis) * p.sign; v > gamepadDeadzone && v > best {
+			best = v
+		}
+	}
+	return math.Min(1, best)
+}
+
+// ParseBinding reads bindings written as "key:W", "mouse:left", "pad:a" or
+// "axis:leftx+"
+func ParseBinding(s string) (Binding, error) {
+	kind, name, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("binding %q should look like kind:name", s)
+	}
+	name = strings.ToLower(name)
+	switch kind {
+	case "key":
+		if k, ok := keysByName[name]; ok {
+			return keyBinding(k), nil
+		}
+	case "mouse":
+		if m, ok := mouseButtonsByName[name]; ok {
+			return mouseBinding(m), nil
+		}
+	case "pad":
+		if b, ok := padButtonsByName[name]; ok {
+			return padButtonBinding(b), nil
+		}
+	case "axis":
+		sign := 1.0
+		if strings.HasSuffix(name, "-") {
+			sign = -1
+		}
+		if a, ok := padAxesByName[strings.TrimRight(name, "+-")]; ok {
+			return padAxisBinding{axis: a, sign: sign}, nil
+		}
+	default:
+		return nil, fmt.Errorf("binding %q: unknown kind %q", s, kind)
+	}
+	return nil, fmt.Errorf("binding %q: unknown %s %q", s, kind, name)
+}
+
+// InputState is everything read from the devices in one tick
+type InputState struct {
+	Held    sim.Actions
+	MoveX   float64
+	MoveY   float64
+	CursorX int
+	CursorY int
+	Wheel   float64
+}
+
+type Input struct {
+	bindings map[sim.InputAction][]Binding
+	cursor   func() (int, int)
+	gamepads []ebiten.GamepadID
+
+	prev, cur InputState
+	// actions already handled this tick, by the UI say, read as released to
+	// anything asking after
+	consumed      map[sim.InputAction]bool
+	wheelConsumed bool
+}
+
+func NewInput(config map[sim.InputAction][]string, cursor func() (int, int)) (*Input, error) {
+	in := &Input{
+		bindings: make(map[sim.InputAction][]Binding),
+		cursor:   cursor,
+	}
+	for action, bindings := range config {
+		for _, raw := range bindings {
+			binding, err := ParseBinding(raw)
+			if err != nil {
+				return nil, fmt.Errorf("action %s: %w", action, err)
+			}
+			in.bindings[action] = append(in.bindings[action], binding)
+		}
+	}
+	return in, nil
+}
+
+func (in *Input) value(action sim.InputAction) float64 {
+	best := 0.0
+	for _, binding := range in.bindings[action] {
+		best = math.Max(best, binding.Value(in.gamepads))
+	}
+	return best
+}
+
+// Update polls every device, once per tick before the scene updates
+func (in *Input) Update() {
+	in.Push(in.poll())
+}
+
+// Push makes state the current tick's input; tests feed states through
+// here instead of polling
+func (in *Input) Push(state InputState) {
+	in.prev = in.cur
+	in.cur = state
+	in.consumed = nil
+	in.wheelConsumed = false
+}
+
+func (in *Input) poll() InputState {
+	in.gamepads = in.gamepads[:0]
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			in.gamepads = append(in.gamepads, id)
+		}
+	}
+
+	state := InputState{}
+	for _, action := range sim.AllActions {
+		if in.value(action) > 0 {
+			state.Held.Set(action)
+		}
+	}
+	state.MoveX = in.value(sim.ActionMoveRight) - in.value(sim.ActionMoveLeft)
+	state.MoveY = in.value(sim.ActionMoveDown) - in.value(sim.ActionMoveUp)
+	state.CursorX, state.CursorY = in.cursor()
+	_, state.Wheel = ebiten.Wheel()
+	return state
+}
+
+func (in *Input) Pressed(action sim.InputAction) bool {
+	return in.cur.Held.Has(action) && !in.consumed[action]
+}
+
+func (in *Input) JustPressed(action sim.InputAction) bool {
+	return in.cur.Held.Has(action) && !in.prev.Held.Has(action) && !in.consumed[action]
+}
+
+func (in *Input) JustReleased(action sim.InputAction) bool {
+	return !in.cur.Held.Has(action) && in.prev.Held.Has(action) && !in.consumed[action]
+}
+
+// Consume hides action from everything that reads it after this, until the
+// next tick
+func (in *Input) Consume(actions ...sim.InputAction) {
+	if in.consumed == nil {
+		in.consumed = make(map[sim.InputAction]bool)
+	}
+	for _, action := range actions {
+		in.consumed[action] = true
+	}
+}
+
+func (in *Input) ConsumeWheel() {
+	in.wheelConsumed = true
+}
+
+// MoveVector is the requested movement in screen space, up is -y. Its
+// length is at most 1.
+func (in *Input) MoveVector() (float64, float64) {
+	if in.consumed[sim.ActionMoveUp] || in.consumed[sim.ActionMoveDown] || in.consumed[sim.ActionMoveLeft] || in.consumed[sim.ActionMoveRight] {
+		return 0, 0
+	}
+	x, y := in.cur.MoveX, in.cur.MoveY
+	if length := math.Hypot(x, y); length > 1 {
+		return x / length, y / length
+	}
+	return x, y
+}
+
+func (in *Input) Cursor() (int, int) {
+	return in.cur.CursorX, in.cur.CursorY
+}
+
+func (in *Input) Wheel() float64 {
+	if in.wheelConsumed {
+		return 0
+	}
+	return in.cur.Wheel
+}
+
+func inputConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ebitengine-magnetism", "input.json"), nil
+}
+
+// LoadInputConfig reads bindings from path. Actions missing from the file
+// keep their defaults, as does everything if there's no file.
+func LoadInputConfig(path string) (map[sim.InputAction][]string, error) {
+	config := defaultBindings()
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	overrides := make(map[sim.InputAction][]string)
+	if err := json.Unmarshal(raw, &overrides); err != nil {
+		return nil, fmt.Errorf("parsing input config %s: %w", path, err)
+	}
+	for action, bindings := range overrides {
+		if _, ok := config[action]; !ok {
+			return nil, fmt.Errorf("input config %s: unknown action %q", path, action)
+		}
+		config[action] = bindings
+	}
+	return config, nil
+}
+
+// WriteDefaultInputConfig writes the default bindings to path, as a
+// starting point for editing
+func WriteDefaultInputConfig(path string) error {
+	raw, err := json.MarshalIndent(defaultBindings(), "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0644)
+}
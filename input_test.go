@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+func TestDefaultBindingsDisjoint(t *testing.T) {
+	// clicks are the one control shared on purpose, the UI consumes them
+	// before the world sees moveToCursor
+	shared := map[string]bool{"mouse:left": true}
+	owner := make(map[string]sim.InputAction)
+	for _, action := range sim.AllActions {
+		for _, binding := range defaultBindings()[action] {
+			if other, ok := owner[binding]; ok && !shared[binding] {
+				t.Errorf("%s is bound to both %s and %s", binding, other, action)
+			}
+			owner[binding] = action
+		}
+	}
+}
+
+func TestLoadInputConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, []byte(`{"cast": ["key:F"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadInputConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := config[sim.ActionCast]; len(got) != 1 || got[0] != "key:F" {
+		t.Errorf("cast = %v, want the override", got)
+	}
+	if got := config[sim.ActionReel]; len(got) == 0 {
+		t.Errorf("reel lost its default bindings")
+	}
+}
+
+func TestLoadInputConfigUnknownAction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, []byte(`{"cats": ["key:F"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadInputConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `"cats"`) {
+		t.Errorf("err = %v, want it to name the unknown action", err)
+	}
+}
+
+func TestLoadInputConfigMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "input.json")
+	config, err := LoadInputConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config[sim.ActionCast]) == 0 {
+		t.Errorf("a missing file should give the defaults")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("loading wrote %s", path)
+	}
+	if err := WriteDefaultInputConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if written, err := LoadInputConfig(path); err != nil || len(written) != len(config) {
+		t.Errorf("reloading the written defaults gave %d actions, %v", len(written), err)
+	}
+}
+
+func TestLoadInputConfigUnreadable(t *testing.T) {
+	// a directory where the file should be can't be read
+	if _, err := LoadInputConfig(t.TempDir()); err == nil {
+		t.Errorf("expected an error reading a directory")
+	}
+}
@@ -1,16 +1,135 @@
 package main
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+    "errors"
+    "flag"
+    "log"
+    "time"
+
+    "github.com/hajimehoshi/ebiten/v2"
+    "github.com/val-is/ebitengine-magnetism/sim"
+    "github.com/val-is/ebitengine-magnetism/world"
+)
 
 func main() {
-    ebiten.SetWindowSize(960, 540)
+    resourceDir := flag.String("resources", "", "load assets from this directory instead of the embedded resources")
+    devMode := flag.Bool("dev", false, "hot reload changed images (requires -resources)")
+    inputPath := flag.String("input", "", "key binding config file (default: input.json in the user config dir)")
+    writeInput := flag.Bool("write-input", false, "write the default key bindings to the -input file and exit")
+    display := DefaultDisplayConfig()
+    flag.IntVar(&display.Width, "width", display.Width, "logical screen width")
+    flag.IntVar(&display.Height, "height", display.Height, "logical screen height")
+    flag.Float64Var(&display.Zoom, "zoom", display.Zoom, "world zoom factor")
+    flag.BoolVar(&display.Fullscreen, "fullscreen", display.Fullscreen, "start fullscreen (toggle with F11 or Alt+Enter)")
+    flag.BoolVar(&display.IntegerScaling, "integer-scale", display.IntegerScaling, "only scale the screen by whole multiples")
+    seed := flag.Int64("seed", time.Now().Unix(), "world seed")
+    mapgen := flag.String("mapgen", world.DefaultMapGenerator, "map generator: "+world.MapGeneratorNames())
+    recordPath := flag.String("record", "", "record the seed and every tick of the game to this file")
+    replayPath := flag.String("replay", "", "rerun a recording without a window and check the final state matches")
+    savePath := flag.String("save", "", "save file for scrap and upgrades (default: save.json in the user config dir)")
+    flag.Parse()
+
+    fsys, err := OpenAssets(*resourceDir)
+    if err != nil {
+        log.Fatalf("opening assets: %v", err)
+    }
+    source := "embedded resources"
+    if *resourceDir != "" {
+        source = *resourceDir
+    }
+    assets := NewAssetManager(fsys, source)
+    if *devMode {
+        if *resourceDir == "" {
+            log.Printf("-dev has no effect on embedded resources, pass -resources ./resources to hot reload")
+        }
+        go assets.Watch(500 * time.Millisecond)
+    }
+
+    if *replayPath != "" {
+        rec, err := sim.LoadRecording(*replayPath)
+        if err != nil {
+            log.Fatalf("loading replay: %v", err)
+        }
+        biomes, err := loadBiomes(assets)
+        if err != nil {
+            log.Fatalf("loading replay: %v", err)
+        }
+        if err := sim.Replay(rec, biomes); err != nil {
+            log.Fatalf("replay %s failed: %v", *replayPath, err)
+        }
+        log.Printf("replay %s ok: %d ticks, state %s", *replayPath, len(rec.Ticks), rec.FinalHash)
+        return
+    }
+    world.Seed(*seed)
+    generator, err := world.LookupMapGenerator(*mapgen)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    if *inputPath == "" {
+        if *inputPath, err = inputConfigPath(); err != nil {
+            log.Fatalf("finding input config: %v", err)
+        }
+    }
+    if *writeInput {
+        if err := WriteDefaultInputConfig(*inputPath); err != nil {
+            log.Fatalf("writing input config: %v", err)
+        }
+        log.Printf("wrote the default bindings to %s", *inputPath)
+        return
+    }
+    bindings, err := LoadInputConfig(*inputPath)
+    if err != nil {
+        log.Fatalf("loading input config: %v", err)
+    }
+
+    if *savePath == "" {
+        if *savePath, err = sim.SaveFilePath(); err != nil {
+            log.Fatalf("finding save file: %v", err)
+        }
+    }
+    profile, err := sim.LoadProfile(*savePath)
+    if err != nil {
+        log.Fatalf("loading save: %v", err)
+    }
+
+    ebiten.SetWindowSize(display.Width/2, display.Height/2)
     ebiten.SetWindowTitle("ebitengine magnet fishing")
-    ebiten.SetWindowResizingMode(ebiten.WindowResizingModeDisabled)
+    ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+    ebiten.SetFullscreen(display.Fullscreen)
 
-    g := &Game{}
+    g := &Game{
+        assets: assets,
+        display: display,
+        seed: *seed,
+        generator: generator,
+        events: sim.NewEventBus(),
+        profile: profile,
+        savePath: *savePath,
+    }
+    if g.input, err = NewInput(bindings, g.CursorPosition); err != nil {
+        log.Fatalf("loading input config: %v", err)
+    }
     g.nextScene, _ = NewTitleScene(g)
 
-    if err := ebiten.RunGame(g); err != nil {
-        panic(err)
+    var rec *sim.Recording
+    if *recordPath != "" {
+        rec = sim.NewRecording(*seed, *mapgen)
+        g.recording = rec
+    }
+
+    runErr := ebiten.RunGame(g)
+    if rec != nil && g.recordedSession == nil {
+        log.Printf("not saving the recording, the game never started")
+    } else if rec != nil {
+        g.recordedSession.EndRecording()
+        if err := rec.Save(*recordPath); err != nil {
+            log.Printf("saving recording: %v", err)
+        } else {
+            log.Printf("recorded %d ticks to %s", len(rec.Ticks), *recordPath)
+        }
+    }
+    if runErr != nil && !errors.Is(runErr, errQuit) {
+        panic(runErr)
     }
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+const (
+	reelBarWidth  = 120
+	reelBarHeight = 8
+)
+
+// drawReel shows m's tension over its progress as two bars centred on x, y
+func drawReel(screen *ebiten.Image, m *sim.ReelMinigame, x, y float64) {
+	ebitenutil.DrawRect(screen, x-reelBarWidth/2-2, y-2, reelBarWidth+4, 2*reelBarHeight+6, color.RGBA{0x10, 0x10, 0x10, 0xc0})
+	tensionColor := color.RGBA{0xe0, 0xc0, 0x40, 0xff}
+	if m.Tension > 0.8 {
+		tensionColor = color.RGBA{0xe0, 0x40, 0x30, 0xff}
+	}
+	ebitenutil.DrawRect(screen, x-reelBarWidth/2, y, reelBarWidth*math.Min(1, m.Tension), reelBarHeight, tensionColor)
+	ebitenutil.DrawRect(screen, x-reelBarWidth/2, y+reelBarHeight+2, reelBarWidth*math.Min(1, m.Progress), reelBarHeight, color.RGBA{0x50, 0xc0, 0x60, 0xff})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+const pauseNoticeTime = 2 * time.Second
+
+// pauseSceneImpl is the Esc menu. Nothing under it updates while it's open,
+// so the world picks up on the exact tick it left off.
+type pauseSceneImpl struct {
+	baseScene
+	ui       *UI
+	settings bool
+	// feedback after saving, shown until noticeExpires
+	notice        string
+	noticeExpires time.Time
+}
+
+func NewPauseScene(game *Game) (Scene, error) {
+	p := &pauseSceneImpl{
+		baseScene: NewBaseScene(game),
+		ui:        NewUI(game.input, game.Fonts()),
+	}
+	p.ui.Keyboard = true
+	return p, nil
+}
+
+func (p *pauseSceneImpl) FreezesBelow() bool {
+	return true
+}
+
+func (p *pauseSceneImpl) Start() error {
+	return nil
+}
+
+func (p *pauseSceneImpl) Stop() error {
+	return nil
+}
+
+func (p *pauseSceneImpl) Update() error {
+	if err := p.baseScene.Update(); err != nil {
+		return err
+	}
+	input := p.game.input
+	if input.JustPressed(sim.ActionMenu) {
+		input.Consume(sim.AllActions...)
+		if p.settings {
+			p.showSettings(false)
+			return nil
+		}
+		return p.game.PopScene()
+	}
+
+	fonts := p.game.Fonts()
+	w, h := p.game.display.Width, p.game.display.Height
+	buttonW := w / 5
+	buttonH := lineHeight(fonts.Body) + 2*uiPad
+	rows := 5
+	if p.settings {
+		rows = 3
+	}
+	panelH := lineHeight(fonts.Title) + rows*(buttonH+uiPad) + lineHeight(fonts.Small) + 4*uiPad
+	panel := image.Rect(w/2-buttonW/2-2*uiPad, h/2-panelH/2, w/2+buttonW/2+2*uiPad, h/2+panelH/2)
+
+	p.ui.Begin()
+	p.ui.draw(func(screen *ebiten.Image) {
+		fillRect(screen, image.Rect(0, 0, w, h), color.RGBA{0, 0, 0, 0x80})
+	})
+	p.ui.Panel(panel)
+	title := "paused"
+	if p.settings {
+		title = "settings"
+	}
+	p.ui.Label(title, w/2, panel.Min.Y+uiPad, TextStyle{Face: fonts.Title, Align: AlignCenter, Color: uiFocusColor, Shadow: color.Black})
+	y := panel.Min.Y + uiPad + lineHeight(fonts.Title) + uiPad
+	button := func(label string) bool {
+		r := image.Rect(w/2-buttonW/2, y, w/2+buttonW/2, y+buttonH)
+		y += buttonH + uiPad
+		return p.ui.Button(r, label, true)
+	}
+
+	var err error
+	if p.settings {
+		display := &p.game.display
+		if button(fmt.Sprintf("fullscreen: %s", onOff(display.Fullscreen))) {
+			display.Fullscreen = !display.Fullscreen
+			ebiten.SetFullscreen(display.Fullscreen)
+		}
+		if button(fmt.Sprintf("integer scaling: %s", onOff(display.IntegerScaling))) {
+			display.IntegerScaling = !display.IntegerScaling
+		}
+		if button("back") {
+			p.showSettings(false)
+		}
+	} else {
+		if button("resume") {
+			err = p.game.PopScene()
+		}
+		if button("settings") {
+			p.showSettings(true)
+		}
+		if button("save") {
+			p.notice = "saved"
+			if p.game.savePath == "" {
+				p.notice = "this session doesn't save"
+			}
+			p.game.SaveProfile()
+			p.noticeExpires = p.clock.Now().Add(pauseNoticeTime)
+		}
+		if button("quit to title") {
+			err = p.quitToTitle()
+		}
+		if button("quit") {
+			p.game.SaveProfile()
+			err = errQuit
+		}
+	}
+	if p.clock.Now().Before(p.noticeExpires) {
+		p.ui.Label(p.notice, w/2, y, TextStyle{Face: fonts.Small, Align: AlignCenter, Color: uiDimTextColor})
+	}
+	p.ui.End()
+	input.Consume(sim.AllActions...)
+	input.ConsumeWheel()
+	return err
+}
+
+// showSettings swaps between the main menu and settings, focusing the top
+// button of whichever is showing
+func (p *pauseSceneImpl) showSettings(show bool) {
+	p.settings = show
+	p.ui.focus = 0
+}
+
+func (p *pauseSceneImpl) quitToTitle() error {
+	title, err := NewTitleScene(p.game)
+	if err != nil {
+		return err
+	}
+	p.game.nextScene = title
+	return nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (p *pauseSceneImpl) Draw(screen *ebiten.Image) {
+	p.ui.Draw(screen)
+}
@@ -1,14 +1,59 @@
 package main
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+    "errors"
+    "log"
+
+    "github.com/hajimehoshi/ebiten/v2"
+    "github.com/hajimehoshi/ebiten/v2/inpututil"
+    "github.com/val-is/ebitengine-magnetism/sim"
+    "github.com/val-is/ebitengine-magnetism/world"
+)
+
+// errQuit ends the game loop from inside a scene
+var errQuit = errors.New("quit")
 
 type Game struct {
     currentScene Scene
     nextScene Scene
+    // drawn over currentScene, last on top; the scenes under an overlay keep
+    // running without being stopped and restarted
+    overlays []Scene
+    assets *AssetManager
+    input *Input
+    display DisplayConfig
+    presenter presenter
+    seed int64
+    generator world.MapGenerator
+    events *sim.EventBus
+    profile *sim.Profile
+    // where the profile is saved, empty to never save
+    savePath string
+    // handed to the first game scene to start, which records its session
+    recording *sim.Recording
+    recordedSession *sim.Session
+    fonts *Fonts
 }
 
 func (g *Game) Update() error {
+    g.assets.Update()
+    if inpututil.IsKeyJustPressed(ebiten.KeyF11) ||
+        (inpututil.IsKeyJustPressed(ebiten.KeyEnter) && ebiten.IsKeyPressed(ebiten.KeyAlt)) {
+        g.display.Fullscreen = !ebiten.IsFullscreen()
+        ebiten.SetFullscreen(g.display.Fullscreen)
+    }
+    g.input.Update()
+    return g.step()
+}
+
+// step advances every scene one tick using whatever input was last pushed
+func (g *Game) step() error {
     if g.nextScene != nil {
+        for len(g.overlays) > 0 {
+            if err := g.PopScene(); err != nil {
+                return err
+            }
+        }
         if g.currentScene != nil {
             if err := g.currentScene.Stop(); err != nil {
                 return err
@@ -20,15 +65,79 @@ func (g *Game) Update() error {
             return err
         }
     }
-    return g.currentScene.Update()
+    // top down, so an overlay can consume input before the scenes under it
+    // see it. Scenes pushed this tick start updating next tick.
+    scenes := g.scenes()
+    for i := len(scenes) - 1; i >= 0; i-- {
+        if err := scenes[i].Update(); err != nil {
+            return err
+        }
+        if f, ok := scenes[i].(freezer); ok && f.FreezesBelow() {
+            break
+        }
+    }
+    return nil
+}
+
+// freezer is an overlay that stops everything under it updating, clocks and
+// timers included, until it's popped
+type freezer interface {
+    FreezesBelow() bool
+}
+
+// scenes is the current scene then every overlay, bottom to top
+func (g *Game) scenes() []Scene {
+    return append([]Scene{g.currentScene}, g.overlays...)
+}
+
+// PushScene starts scene as an overlay on top of everything else
+func (g *Game) PushScene(scene Scene) error {
+    g.overlays = append(g.overlays, scene)
+    return scene.Start()
+}
+
+// PopScene stops the top overlay, uncovering whatever was under it
+func (g *Game) PopScene() error {
+    if len(g.overlays) == 0 {
+        return nil
+    }
+    top := g.overlays[len(g.overlays)-1]
+    g.overlays = g.overlays[:len(g.overlays)-1]
+    return top.Stop()
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-    g.currentScene.Draw(screen)
+    g.presenter.offscreen.Clear()
+    for _, scene := range g.scenes() {
+        scene.Draw(g.presenter.offscreen)
+    }
+    g.presenter.present(screen)
 }
 
 func (g *Game) Layout(oW, oH int) (sW, sH int) {
-	return 1920, 1080
+    return g.presenter.layout(g.display, oW, oH)
+}
+
+func (g *Game) SaveProfile() {
+    if g.savePath == "" {
+        return
+    }
+    if err := g.profile.Save(g.savePath); err != nil {
+        log.Printf("saving profile: %v", err)
+    }
+}
+
+// Fonts loads the shared fonts the first time a scene asks for them
+func (g *Game) Fonts() *Fonts {
+    if g.fonts == nil {
+        g.fonts = LoadFonts(g.assets)
+    }
+    return g.fonts
+}
+
+// CursorPosition is ebiten.CursorPosition in logical screen coordinates
+func (g *Game) CursorPosition() (int, int) {
+    return g.presenter.toLogical(ebiten.CursorPosition())
 }
 
 type Scene interface {
@@ -39,7 +148,8 @@ type Scene interface {
 }
 
 type baseScene struct {
-    actionQueue ActionQueue
+    actionQueue sim.ActionQueue
+    clock *sim.TickClock
     game *Game
 }
     
@@ -48,10 +158,10 @@ func (b *baseScene) Update() error {
 } 
 
 func NewBaseScene(game *Game) baseScene {
+    clock := &sim.TickClock{}
     return baseScene{
-        actionQueue: ActionQueue{
-            actions: make(map[int64]Action),
-        },
+        actionQueue: sim.NewActionQueue(clock),
+        clock: clock,
         game: game,
     }
 }
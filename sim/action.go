@@ -1,6 +1,7 @@
-package main
+package sim
 
 import (
+	"sort"
 	"time"
 )
 
@@ -12,12 +13,27 @@ var (
 
 type ActionQueue struct {
     actions map[int64]Action
+    clock *TickClock
+}
+
+func NewActionQueue(clock *TickClock) ActionQueue {
+    return ActionQueue{
+        actions: make(map[int64]Action),
+        clock: clock,
+    }
 }
 
 func (a *ActionQueue) Update() error {
+    a.clock.Tick()
+    // run in the order actions were added so a replayed run matches
+    keys := make([]int64, 0, len(a.actions))
+    for key := range a.actions {
+        keys = append(keys, key)
+    }
+    sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
     finishedActions := make([]int64, 0)
-    for key, action := range a.actions {
-        if complete, err := action(); err != nil {
+    for _, key := range keys {
+        if complete, err := a.actions[key](); err != nil {
             return err
         } else if complete {
             finishedActions = append(finishedActions, key)
@@ -34,11 +50,11 @@ func (a *ActionQueue) Add(action Action) {
     curActionKey++
 }
 
-func NewContinuousTimedAction(f func(percentComplete float64, duration time.Duration) (doneEarly bool, err error), duration time.Duration) Action {
-    startTime := time.Now()
+func NewContinuousTimedAction(clock *TickClock, f func(percentComplete float64, duration time.Duration) (doneEarly bool, err error), duration time.Duration) Action {
+    startTime := clock.Now()
     endTime := startTime.Add(duration)
     return func() (bool, error) {
-        curTime := time.Now()
+        curTime := clock.Now()
         if curTime.After(endTime) {
             return true, nil
         }
@@ -52,9 +68,9 @@ func NewContinuousTimedAction(f func(percentComplete float64, duration time.Dura
     }
 }
 
-func NewTimerAction(f func() error, runTime time.Time) Action {
+func NewTimerAction(clock *TickClock, f func() error, runTime time.Time) Action {
     return func() (bool, error) {
-        if time.Now().After(runTime) {
+        if clock.Now().After(runTime) {
             return true, f()
         }
         return false, nil
@@ -0,0 +1,32 @@
+package sim
+
+import "time"
+
+const (
+	TickRate = 60
+	TickDt   = 1.0 / TickRate
+)
+
+var tickEpoch = time.Unix(0, 0)
+
+func DurationTicks(d time.Duration) int {
+	return int(d.Seconds() * TickRate)
+}
+
+// TickClock is simulated time that only moves when its ActionQueue updates,
+// so timers behave the same live, in replays and while paused
+type TickClock struct {
+	ticks int64
+}
+
+func (c *TickClock) Tick() {
+	c.ticks++
+}
+
+func (c *TickClock) Ticks() int64 {
+	return c.ticks
+}
+
+func (c *TickClock) Now() time.Time {
+	return tickEpoch.Add(time.Duration(c.ticks) * time.Second / TickRate)
+}
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+type ItemID string
+
+const (
+	ItemSensor        ItemID = "sensor"
+	ItemElectromagnet ItemID = "electromagnet"
+	ItemAntenna       ItemID = "antenna"
+)
+
+type Recipe struct {
+	Item        ItemID
+	Name        string
+	Description string
+	Cost        map[world.ScrapType]int
+	// how long the crafting animation runs
+	Time time.Duration
+}
+
+// Recipes is every recipe, in menu order
+var Recipes = []*Recipe{
+	{
+		Item:        ItemSensor,
+		Name:        "Sensor",
+		Description: "Electronics wired up to pick up metal nearby.",
+		Cost:        map[world.ScrapType]int{world.SCRAP_ELEC: 1, world.SCRAP_WIRE: 1},
+		Time:        1 * time.Second,
+	},
+	{
+		Item:        ItemElectromagnet,
+		Name:        "Electromagnet",
+		Description: "Scrap metal wound with electronics into a magnet that can be switched on and off.",
+		Cost:        map[world.ScrapType]int{world.SCRAP_ELEC: 1, world.SCRAP_SCRAP: 2},
+		Time:        1500 * time.Millisecond,
+	},
+	{
+		Item:        ItemAntenna,
+		Name:        "Antenna",
+		Description: "Scrap and a lot of wire, enough to call for a way off the island.",
+		Cost:        map[world.ScrapType]int{world.SCRAP_SCRAP: 3, world.SCRAP_WIRE: 3},
+		Time:        3 * time.Second,
+	},
+}
+
+// CanCraft is nil if the inventory covers r
+func (p *Profile) CanCraft(r *Recipe) error {
+	if !p.Inventory.Has(r.Cost) {
+		return fmt.Errorf("%s costs %s, have %s", r.Name, FormatScrap(r.Cost), FormatScrap(p.Inventory))
+	}
+	return nil
+}
+
+// Craft spends r's scrap; the item is only added by Finish, once it's been
+// put together
+func (p *Profile) Craft(r *Recipe) error {
+	if err := p.CanCraft(r); err != nil {
+		return err
+	}
+	p.Inventory.Spend(r.Cost)
+	return nil
+}
+
+func (p *Profile) Finish(r *Recipe) {
+	p.Items[r.Item]++
+}
+
+func findRecipe(item ItemID) *Recipe {
+	for _, r := range Recipes {
+		if r.Item == item {
+			return r
+		}
+	}
+	return nil
+}
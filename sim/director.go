@@ -0,0 +1,97 @@
+package sim
+
+import (
+	"math"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+const (
+	maxActiveScrap = 4
+
+	// spawn weight halves every this many tiles from the player
+	scrapProximityFalloff = 6.0
+	// an active bobber pulls spawns toward itself on top of that, halving
+	// every scrapBobberFalloff tiles before detection upgrades
+	scrapBobberPull    = 2.0
+	scrapBobberFalloff = 2.0
+	// so far off water still gets the odd spawn
+	scrapMinWeight = 0.001
+
+	// catches and misses older than this stop counting toward the pace
+	scrapPaceWindow = 60 * time.Second
+	// each recent catch shortens the spawn period by this fraction of itself,
+	// each scrap left to expire lengthens it
+	scrapCatchSpeedup = 0.25
+	scrapMissSlowdown = 0.2
+	scrapMinPace      = 0.4
+	scrapMaxPace      = 2.0
+)
+
+// SpawnDirector decides where and how often scrap turns up: near the player,
+// never too much at once, faster for a player catching everything and slower
+// for one letting it sink
+type SpawnDirector struct {
+	clock   *TickClock
+	catches []time.Time
+	misses  []time.Time
+}
+
+func NewSpawnDirector(clock *TickClock) *SpawnDirector {
+	return &SpawnDirector{clock: clock}
+}
+
+// Observe keeps track of how scrap leaves the map
+func (d *SpawnDirector) Observe(e ScrapDespawned) {
+	switch e.Reason {
+	case DespawnCaught:
+		d.catches = append(d.catches, d.clock.Now())
+	case DespawnExpired:
+		d.misses = append(d.misses, d.clock.Now())
+	}
+}
+
+func (d *SpawnDirector) recent(times []time.Time) []time.Time {
+	cutoff := d.clock.Now().Add(-scrapPaceWindow)
+	for len(times) > 0 && times[0].Before(cutoff) {
+		times = times[1:]
+	}
+	return times
+}
+
+// Pace multiplies the spawn period, below 1 spawns faster
+func (d *SpawnDirector) Pace() float64 {
+	d.catches = d.recent(d.catches)
+	d.misses = d.recent(d.misses)
+	pace := (1 + float64(len(d.misses))*scrapMissSlowdown) / (1 + float64(len(d.catches))*scrapCatchSpeedup)
+	return math.Max(scrapMinPace, math.Min(scrapMaxPace, pace))
+}
+
+func (d *SpawnDirector) NextDelay() time.Duration {
+	base := sampleTimeDuration(scrapSpawnPeriodMin, scrapSpawnPeriodMax)
+	return time.Duration(float64(base) * d.Pace())
+}
+
+func (d *SpawnDirector) CanSpawn(active int) bool {
+	return active < maxActiveScrap
+}
+
+// PickTile chooses among candidates, weighting each by closeness to the
+// player and to the bobber if there is one, whose pull falls off over
+// bobberRadius, then by richness of the waters it's in. candidates must be
+// in a fixed order for replays to match.
+func (d *SpawnDirector) PickTile(candidates []world.IsometricCoordinate, player world.IsometricCoordinate, bobber *world.IsometricCoordinate, bobberRadius float64, richness func(world.IsometricCoordinate) float64) (world.IsometricCoordinate, bool) {
+	table := world.NewWeightedTable[world.IsometricCoordinate]()
+	for _, coord := range candidates {
+		weight := math.Exp2(-math.Hypot(coord.X-player.X, coord.Y-player.Y) / scrapProximityFalloff)
+		if bobber != nil {
+			weight += scrapBobberPull * math.Exp2(-math.Hypot(coord.X-bobber.X, coord.Y-bobber.Y)/bobberRadius)
+		}
+		table.Add(coord, math.Max(scrapMinWeight, weight)*richness(coord))
+	}
+	if table.Len() == 0 {
+		return world.IsometricCoordinate{}, false
+	}
+	return table.Pick(), true
+}
@@ -0,0 +1,103 @@
+package sim
+
+import (
+	"reflect"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+// EventBus passes events to whoever subscribed to their type. Handlers run
+// synchronously inside Publish, in the order they subscribed.
+type EventBus struct {
+	handlers map[reflect.Type][]*eventHandler
+}
+
+type eventHandler struct {
+	handle func(any)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[reflect.Type][]*eventHandler)}
+}
+
+// Subscribe calls handler with every published T; call the returned func to
+// stop
+func Subscribe[T any](bus *EventBus, handler func(T)) func() {
+	key := reflect.TypeOf((*T)(nil)).Elem()
+	h := &eventHandler{handle: func(event any) { handler(event.(T)) }}
+	bus.handlers[key] = append(bus.handlers[key], h)
+	return func() {
+		handlers := bus.handlers[key]
+		for i, other := range handlers {
+			if other == h {
+				bus.handlers[key] = append(handlers[:i:i], handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func Publish[T any](bus *EventBus, event T) {
+	key := reflect.TypeOf((*T)(nil)).Elem()
+	// copy so handlers can unsubscribe while being called
+	for _, h := range append([]*eventHandler(nil), bus.handlers[key]...) {
+		h.handle(event)
+	}
+}
+
+// ScrapSpawned is published when scrap washes up on a water tile
+type ScrapSpawned struct {
+	Coord world.IsometricCoordinate
+	Type  world.ScrapType
+}
+
+type DespawnReason int
+
+const (
+	DespawnExpired DespawnReason = iota
+	DespawnCaught
+)
+
+// ReelFinished is published when a reel minigame ends, whether or not the
+// scrap was landed
+type ReelFinished struct {
+	Type   world.ScrapType
+	Result ReelResult
+}
+
+// UpgradePurchased is published when the player buys an upgrade level
+type UpgradePurchased struct {
+	ID    UpgradeID
+	Level int
+}
+
+// ItemCrafted is published when a crafted item is finished
+type ItemCrafted struct {
+	Item ItemID
+}
+
+// ScrapDespawned is published when scrap leaves its tile, sinking or reeled in
+type ScrapDespawned struct {
+	Coord  world.IsometricCoordinate
+	Type   world.ScrapType
+	Reason DespawnReason
+}
+
+// CastMissed is published when a cast would land somewhere the bobber can't
+// go
+type CastMissed struct {
+	Target world.IsometricCoordinate
+}
+
+// UpgradeRefused is published when the player asks for an upgrade they
+// can't buy yet
+type UpgradeRefused struct {
+	ID     UpgradeID
+	Reason error
+}
+
+// TilesChanged is published when the map's tiles are swapped out, as the
+// intro starts growing it and once it's done
+type TilesChanged struct {
+	Tiles []*world.Tile
+}
@@ -0,0 +1,122 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type InputAction string
+
+const (
+	ActionMoveUp       InputAction = "moveUp"
+	ActionMoveDown     InputAction = "moveDown"
+	ActionMoveLeft     InputAction = "moveLeft"
+	ActionMoveRight    InputAction = "moveRight"
+	ActionMoveToCursor InputAction = "moveToCursor"
+	ActionCast         InputAction = "cast"
+	ActionReel         InputAction = "reel"
+	ActionInteract     InputAction = "interact"
+	ActionMenu         InputAction = "menu"
+	ActionZoomIn       InputAction = "zoomIn"
+	ActionZoomOut      InputAction = "zoomOut"
+	ActionBuyMagnet    InputAction = "buyMagnet"
+	ActionBuyCastRange InputAction = "buyCastRange"
+	ActionBuyReelSpeed InputAction = "buyReelSpeed"
+	ActionBuyDetection InputAction = "buyDetection"
+	ActionBuyWalkSpeed InputAction = "buyWalkSpeed"
+	ActionClick        InputAction = "click"
+	ActionCraft        InputAction = "craft"
+)
+
+// AllActions is every action; order matters, it's the bit position of each
+// action in Actions
+var AllActions = []InputAction{
+	ActionMoveUp,
+	ActionMoveDown,
+	ActionMoveLeft,
+	ActionMoveRight,
+	ActionMoveToCursor,
+	ActionCast,
+	ActionReel,
+	ActionInteract,
+	ActionMenu,
+	ActionZoomIn,
+	ActionZoomOut,
+	ActionBuyMagnet,
+	ActionBuyCastRange,
+	ActionBuyReelSpeed,
+	ActionBuyDetection,
+	ActionBuyWalkSpeed,
+	ActionClick,
+	ActionCraft,
+}
+
+func actionBit(action InputAction) int {
+	for bit, a := range AllActions {
+		if a == action {
+			return bit
+		}
+	}
+	return -1
+}
+
+// Actions is a set of actions, a bit each
+type Actions uint32
+
+func (a Actions) Has(action InputAction) bool {
+	bit := actionBit(action)
+	return bit >= 0 && a&(1<<bit) != 0
+}
+
+func (a *Actions) Set(action InputAction) {
+	if bit := actionBit(action); bit >= 0 {
+		*a |= 1 << bit
+	}
+}
+
+// MarshalJSON lists the actions by name, so reordering AllActions doesn't
+// change what old recordings pressed
+func (a Actions) MarshalJSON() ([]byte, error) {
+	names := make([]InputAction, 0)
+	for _, action := range AllActions {
+		if a.Has(action) {
+			names = append(names, action)
+		}
+	}
+	return json.Marshal(names)
+}
+
+func (a *Actions) UnmarshalJSON(raw []byte) error {
+	names := make([]InputAction, 0)
+	if err := json.Unmarshal(raw, &names); err != nil {
+		return err
+	}
+	*a = 0
+	for _, action := range names {
+		if actionBit(action) < 0 {
+			return fmt.Errorf("unknown action %q", action)
+		}
+		a.Set(action)
+	}
+	return nil
+}
+
+// Command is something asked for through a menu rather than a bound action
+type Command struct {
+	Buy   UpgradeID `json:"buy,omitempty"`
+	Craft ItemID    `json:"craft,omitempty"`
+}
+
+// Controls is everything a Session reads in one tick: the input left over
+// once any UI has taken what it wanted, with movement already turned from
+// the screen onto the ground, and any menu commands
+type Controls struct {
+	Held Actions `json:"held,omitempty"`
+	// the held actions that weren't held last tick
+	Pressed Actions `json:"pressed,omitempty"`
+	// which way to walk across the ground in tiles, length at most 1 for
+	// full speed
+	MoveX    float64   `json:"moveX,omitempty"`
+	MoveY    float64   `json:"moveY,omitempty"`
+	Commands []Command `json:"commands,omitempty"`
+}
@@ -0,0 +1,31 @@
+package sim
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestControlsJSONByName(t *testing.T) {
+	c := Controls{MoveX: 0.5, Commands: []Command{{Buy: UpgradeMagnet}}}
+	c.Held.Set(ActionCast)
+	c.Held.Set(ActionBuyWalkSpeed)
+	c.Pressed.Set(ActionBuyWalkSpeed)
+	raw, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"held":["cast","buyWalkSpeed"]`) {
+		t.Errorf("held actions should be saved by name: %s", raw)
+	}
+	var back Controls
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Held != c.Held || back.Pressed != c.Pressed || back.MoveX != c.MoveX || len(back.Commands) != 1 || back.Commands[0] != c.Commands[0] {
+		t.Errorf("round trip gave %+v, want %+v", back, c)
+	}
+	if err := json.Unmarshal([]byte(`{"held":["cats"]}`), &back); err == nil || !strings.Contains(err.Error(), `"cats"`) {
+		t.Errorf("err = %v, want it to name the unknown action", err)
+	}
+}
@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"math"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+type ReelResult int
+
+const (
+	ReelInProgress ReelResult = iota
+	ReelCaught
+	// tension maxed out
+	ReelSnapped
+	// line went slack for too long and the magnet lost its grip
+	ReelSlipped
+)
+
+const (
+	reelStartTension = 0.3
+	// per second while reeling, times the scrap's weight
+	reelTensionRise = 0.3
+	// per second while not reeling
+	reelTensionFall = 0.5
+	// the scrap tugs back in surges, up to this much per second at full weight
+	reelTugStrength = 0.3
+	reelTugPeriod   = 1.7 // seconds
+	// per second while reeling at strength 1 against weight 1, before upgrades
+	reelSpeed = 0.35
+	// scrap drifts back out while the line isn't being reeled
+	reelDriftBack = 0.05
+	// below this tension the line is slack
+	reelSlackTension = 0.05
+	// how long a strength 1 magnet holds on a slack line
+	reelSlackGrace = 1500 * time.Millisecond
+)
+
+// how heavy each scrap type is to reel, heavier pulls harder on the line
+var scrapWeights = map[world.ScrapType]float64{
+	world.SCRAP_SCRAP: 1.0,
+	world.SCRAP_WIRE:  0.7,
+	world.SCRAP_ELEC:  1.3,
+}
+
+// ReelMinigame is the fight to bring hooked scrap in: reeling gains ground
+// but builds tension, easing off lets tension drop but the scrap drifts away
+// and a slack line lets it slip off the magnet. It only reads the tick clock
+// and whether reel is held, so it plays back the same every time.
+type ReelMinigame struct {
+	clock     *TickClock
+	startTick int64
+
+	weight   float64
+	strength float64
+	speed    float64
+
+	Tension    float64
+	Progress   float64
+	slackTicks int
+	Result     ReelResult
+}
+
+func NewReelMinigame(clock *TickClock, scrapType world.ScrapType, stats PlayerStats) *ReelMinigame {
+	weight, ok := scrapWeights[scrapType]
+	if !ok {
+		weight = 1
+	}
+	return &ReelMinigame{
+		clock:     clock,
+		startTick: clock.Ticks(),
+		weight:    weight,
+		strength:  stats.MagnetStrength,
+		speed:     stats.ReelSpeed,
+		Tension:   reelStartTension,
+	}
+}
+
+// tug is how hard the scrap is pulling back this tick, 0 to 1
+func (m *ReelMinigame) tug() float64 {
+	t := float64(m.clock.Ticks()-m.startTick) * TickDt
+	return math.Max(0, math.Sin(2*math.Pi*t/reelTugPeriod))
+}
+
+// Step advances one tick and returns the result so far
+func (m *ReelMinigame) Step(reeling bool) ReelResult {
+	if m.Result != ReelInProgress {
+		return m.Result
+	}
+	if reeling {
+		m.Tension += (reelTensionRise + reelTugStrength*m.tug()) * m.weight * TickDt
+		m.Progress += m.speed * m.strength / m.weight * TickDt
+	} else {
+		m.Tension -= reelTensionFall * TickDt
+		m.Progress -= reelDriftBack * m.weight * TickDt
+	}
+	m.Tension = math.Max(0, m.Tension)
+	m.Progress = math.Max(0, m.Progress)
+
+	if m.Tension < reelSlackTension {
+		m.slackTicks++
+	} else {
+		m.slackTicks = 0
+	}
+
+	switch {
+	case m.Progress >= 1:
+		m.Result = ReelCaught
+	case m.Tension >= 1:
+		m.Result = ReelSnapped
+	case m.slackTicks > int(float64(DurationTicks(reelSlackGrace))*m.strength):
+		m.Result = ReelSlipped
+	}
+	return m.Result
+}
+
+// Action plays the minigame on an ActionQueue, asking reeling each tick
+// whether reel is held and calling done once with the outcome
+func (m *ReelMinigame) Action(reeling func() bool, done func(ReelResult) error) Action {
+	return func() (bool, error) {
+		if result := m.Step(reeling()); result != ReelInProgress {
+			return true, done(result)
+		}
+		return false, nil
+	}
+}
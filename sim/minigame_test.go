@@ -0,0 +1,84 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+// a minute of ticks, every reel is decided well before this
+const reelTestTicks = 60 * TickRate
+
+// playReel steps m with the clock until it's decided, asking reel each tick
+// whether to hold the button, and returns the result and how many ticks it
+// took
+func playReel(t *testing.T, clock *TickClock, m *ReelMinigame, reel func(m *ReelMinigame) bool) (ReelResult, int) {
+	t.Helper()
+	for tick := 1; tick <= reelTestTicks; tick++ {
+		clock.Tick()
+		if result := m.Step(reel(m)); result != ReelInProgress {
+			return result, tick
+		}
+	}
+	t.Fatalf("reel still going after %d ticks: tension %.2f progress %.2f", reelTestTicks, m.Tension, m.Progress)
+	return ReelInProgress, reelTestTicks
+}
+
+// reelSteadily holds reel until the tension gets high, then eases off
+func reelSteadily(m *ReelMinigame) bool {
+	return m.Tension < 0.7
+}
+
+func TestReelCaught(t *testing.T) {
+	for _, scrapType := range []world.ScrapType{world.SCRAP_SCRAP, world.SCRAP_WIRE, world.SCRAP_ELEC} {
+		clock := &TickClock{}
+		m := NewReelMinigame(clock, scrapType, NewProfile().Stats())
+		if result, ticks := playReel(t, clock, m, reelSteadily); result != ReelCaught {
+			t.Errorf("scrap type %d: reeling steadily gave %d after %d ticks, want caught", scrapType, result, ticks)
+		}
+	}
+}
+
+func TestReelSnapped(t *testing.T) {
+	clock := &TickClock{}
+	m := NewReelMinigame(clock, world.SCRAP_ELEC, NewProfile().Stats())
+	result, _ := playReel(t, clock, m, func(*ReelMinigame) bool { return true })
+	if result != ReelSnapped {
+		t.Errorf("holding reel on heavy scrap gave %d, want snapped", result)
+	}
+	if m.Tension < 1 {
+		t.Errorf("snapped at tension %.2f", m.Tension)
+	}
+}
+
+func TestReelSlipped(t *testing.T) {
+	clock := &TickClock{}
+	stats := NewProfile().Stats()
+	m := NewReelMinigame(clock, world.SCRAP_SCRAP, stats)
+	result, ticks := playReel(t, clock, m, func(*ReelMinigame) bool { return false })
+	if result != ReelSlipped {
+		t.Errorf("leaving the line slack gave %d, want slipped", result)
+	}
+	if grace := int(float64(DurationTicks(reelSlackGrace)) * stats.MagnetStrength); ticks <= grace {
+		t.Errorf("slipped after %d ticks, before the %d tick grace", ticks, grace)
+	}
+}
+
+func TestReelSpeedUpgrade(t *testing.T) {
+	ticksAt := func(level int) int {
+		profile := NewProfile()
+		profile.Upgrades[UpgradeReelSpeed] = level
+		clock := &TickClock{}
+		m := NewReelMinigame(clock, world.SCRAP_SCRAP, profile.Stats())
+		result, ticks := playReel(t, clock, m, reelSteadily)
+		if result != ReelCaught {
+			t.Fatalf("reel speed %d: gave %d, want caught", level, result)
+		}
+		return ticks
+	}
+	base := ticksAt(0)
+	upgraded := ticksAt(FindUpgrade(UpgradeReelSpeed).MaxLevel)
+	if upgraded >= base {
+		t.Errorf("maxed reel speed took %d ticks, no faster than %d without it", upgraded, base)
+	}
+}
@@ -0,0 +1,94 @@
+package sim
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+// 2 stored held actions by name rather than as a bitmask over allActions,
+// 3 records what the session saw from the game starting, rather than every
+// tick of raw input from the title screen
+const recordingVersion = 3
+
+// Recording is everything needed to rerun a session tick for tick: the
+// world seed and generator, the profile it started with and the controls of
+// every tick
+type Recording struct {
+	Version   int    `json:"version"`
+	Seed      int64  `json:"seed"`
+	Generator string `json:"generator,omitempty"`
+	// the profile as the session started, upgrades change how it plays
+	Profile   *Profile   `json:"profile,omitempty"`
+	Ticks     []Controls `json:"ticks"`
+	FinalHash string     `json:"finalHash"`
+}
+
+func NewRecording(seed int64, generator string) *Recording {
+	return &Recording{
+		Version:   recordingVersion,
+		Seed:      seed,
+		Generator: generator,
+	}
+}
+
+func LoadRecording(path string) (*Recording, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Recording{}
+	if err := json.Unmarshal(raw, rec); err != nil {
+		return nil, fmt.Errorf("parsing recording %s: %w", path, err)
+	}
+	if rec.Version != recordingVersion {
+		return nil, fmt.Errorf("recording %s is version %d, expected %d", path, rec.Version, recordingVersion)
+	}
+	return rec, nil
+}
+
+func (r *Recording) Save(path string) error {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0644)
+}
+
+func hashFloats(w io.Writer, values ...float64) {
+	binary.Write(w, binary.LittleEndian, values)
+}
+
+func hashCoord(w io.Writer, c world.IsometricCoordinate) {
+	hashFloats(w, c.X, c.Y, c.Z)
+}
+
+// Replay reruns rec on biomes without drawing anything, then checks the
+// final state against the recording
+func Replay(rec *Recording, biomes *world.BiomeTable) error {
+	generator, err := world.LookupMapGenerator(rec.Generator)
+	if err != nil {
+		return err
+	}
+	profile := rec.Profile
+	if profile == nil {
+		profile = NewProfile()
+	}
+	s := NewSession(rec.Seed, generator, biomes, profile.Clone(), NewEventBus())
+	if err := s.Start(); err != nil {
+		return err
+	}
+	for tick, c := range rec.Ticks {
+		if err := s.Step(c); err != nil {
+			return fmt.Errorf("tick %d: %w", tick, err)
+		}
+	}
+	if got := s.StateHash(); got != rec.FinalHash {
+		return fmt.Errorf("state after %d ticks is %s, recording expects %s", len(rec.Ticks), got, rec.FinalHash)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+func loadBiomes(t *testing.T) *world.BiomeTable {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("..", "resources", "biomes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	biomes, err := world.ParseBiomes(raw, "biomes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return biomes
+}
+
+// every recording under testdata/replay has to play back to the state it
+// was recorded with, so a change that alters the simulation shows up here
+func TestReplays(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "replay", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no recordings in testdata/replay")
+	}
+	biomes := loadBiomes(t)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			rec, err := LoadRecording(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.FinalHash == "" {
+				t.Fatal("recording has no final hash")
+			}
+			if err := Replay(rec, biomes); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// a session recorded while it's played, menu commands and all, replays to
+// the same state after a round trip through the file
+func TestRecordThenReplay(t *testing.T) {
+	biomes := loadBiomes(t)
+	generator, err := world.LookupMapGenerator("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := NewProfile()
+	profile.Inventory.Add(world.SCRAP_SCRAP, 4)
+	rec := NewRecording(7, "")
+	s := NewSession(7, generator, biomes, profile, NewEventBus())
+	s.Record(rec)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for tick := 0; tick < 10*TickRate; tick++ {
+		c := Controls{MoveX: 1}
+		if tick%90 < 30 {
+			c.Held.Set(ActionCast)
+		}
+		if tick == 5*TickRate {
+			s.Issue(Command{Buy: UpgradeWalkSpeed})
+		}
+		if err := s.Step(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Stop()
+	if s.Profile().Upgrades[UpgradeWalkSpeed] != 1 {
+		t.Errorf("the walk speed command wasn't run")
+	}
+	path := filepath.Join(t.TempDir(), "run.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Replay(loaded, biomes); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,182 @@
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+const profileVersion = 1
+
+// Inventory counts scrap by type; it's saved by scrap name so reordering
+// ScrapType doesn't scramble old saves
+type Inventory map[world.ScrapType]int
+
+func (inv Inventory) Add(scrapType world.ScrapType, n int) {
+	inv[scrapType] += n
+}
+
+func (inv Inventory) Has(cost map[world.ScrapType]int) bool {
+	for scrapType, n := range cost {
+		if inv[scrapType] < n {
+			return false
+		}
+	}
+	return true
+}
+
+func (inv Inventory) Spend(cost map[world.ScrapType]int) {
+	for scrapType, n := range cost {
+		inv[scrapType] -= n
+	}
+}
+
+func scrapTypeName(scrapType world.ScrapType) string {
+	for name, t := range world.ScrapTypeNames {
+		if t == scrapType {
+			return name
+		}
+	}
+	return fmt.Sprintf("scrap%d", scrapType)
+}
+
+// FormatScrap lists counts like "2 elec, 3 scrap", by name
+func FormatScrap(counts map[world.ScrapType]int) string {
+	types := make([]world.ScrapType, 0, len(counts))
+	for scrapType, n := range counts {
+		if n != 0 {
+			types = append(types, scrapType)
+		}
+	}
+	if len(types) == 0 {
+		return "nothing"
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return scrapTypeName(types[i]) < scrapTypeName(types[j])
+	})
+	parts := make([]string, len(types))
+	for i, scrapType := range types {
+		parts[i] = fmt.Sprintf("%d %s", counts[scrapType], scrapTypeName(scrapType))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (inv Inventory) MarshalJSON() ([]byte, error) {
+	byName := make(map[string]int, len(inv))
+	for scrapType, n := range inv {
+		byName[scrapTypeName(scrapType)] = n
+	}
+	return json.Marshal(byName)
+}
+
+func (inv *Inventory) UnmarshalJSON(raw []byte) error {
+	byName := make(map[string]int)
+	if err := json.Unmarshal(raw, &byName); err != nil {
+		return err
+	}
+	*inv = make(Inventory, len(byName))
+	for name, n := range byName {
+		scrapType, ok := world.ScrapTypeNames[name]
+		if !ok {
+			return fmt.Errorf("unknown scrap %q", name)
+		}
+		(*inv)[scrapType] = n
+	}
+	return nil
+}
+
+// Profile is the progress kept between runs
+type Profile struct {
+	Version   int               `json:"version"`
+	Inventory Inventory         `json:"inventory"`
+	Upgrades  map[UpgradeID]int `json:"upgrades"`
+	// crafted items
+	Items map[ItemID]int `json:"items,omitempty"`
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		Version:   profileVersion,
+		Inventory: make(Inventory),
+		Upgrades:  make(map[UpgradeID]int),
+		Items:     make(map[ItemID]int),
+	}
+}
+
+func (p *Profile) Stats() PlayerStats {
+	return statsFor(p.Upgrades)
+}
+
+func (p *Profile) Clone() *Profile {
+	clone := NewProfile()
+	for scrapType, n := range p.Inventory {
+		clone.Inventory[scrapType] = n
+	}
+	for id, level := range p.Upgrades {
+		clone.Upgrades[id] = level
+	}
+	for item, n := range p.Items {
+		clone.Items[item] = n
+	}
+	return clone
+}
+
+// SaveFilePath is save.json in the user config dir
+func SaveFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ebitengine-magnetism", "save.json"), nil
+}
+
+// LoadProfile reads a save, or starts a fresh profile if there isn't one yet
+func LoadProfile(path string) (*Profile, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewProfile(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	profile := NewProfile()
+	if err := json.Unmarshal(raw, profile); err != nil {
+		return nil, fmt.Errorf("parsing save %s: %w", path, err)
+	}
+	if profile.Version != profileVersion {
+		return nil, fmt.Errorf("save %s is version %d, expected %d", path, profile.Version, profileVersion)
+	}
+	if profile.Inventory == nil {
+		profile.Inventory = make(Inventory)
+	}
+	if profile.Upgrades == nil {
+		profile.Upgrades = make(map[UpgradeID]int)
+	}
+	if profile.Items == nil {
+		profile.Items = make(map[ItemID]int)
+	}
+	return profile, nil
+}
+
+// Save writes to a temporary file first so a crash mid-write can't eat the
+// old save
+func (p *Profile) Save(path string) error {
+	raw, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,667 @@
+package sim
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+const (
+	// before upgrades, in tiles per tick
+	walkSpeed = 5.0 / 60.0
+	// player's z above the ground it stands on
+	playerStandHeight = 0.5
+
+	playerCollisionRadius = 0.2
+	treeCollisionRadius   = 0.25
+
+	// a tap casts castMinRange tiles out, holding for castChargeTime reaches
+	// the cast range, castRange before upgrades
+	castMinRange   = 1
+	castRange      = 5
+	castChargeTime = 1.0 // seconds
+
+	// how long the map takes to grow in when the game starts
+	mapIntroTime = 3 * time.Second
+
+	scrapMinLife = 30 * time.Second
+	scrapMaxLife = 60 * time.Second
+
+	scrapSpawnPeriodMin = 15 * time.Second
+	scrapSpawnPeriodMax = 30 * time.Second
+
+	scrapDepthRarity = 0.5
+)
+
+var (
+	bobPositions = []float64{-1, 0, 1, 0, 0, 1, 1, 2, 1, 0, 0, 0}
+	// before upgrades
+	bobDelay = 500 * time.Millisecond
+)
+
+// Player is where the player stands and which way they last walked
+type Player struct {
+	Pos     world.IsometricCoordinate
+	Heading world.IsometricCoordinate
+	// 0 to 1 while the cast button is held
+	CastCharge float64
+}
+
+// Bobber carries the magnet out onto the water, bobbing while it's there
+type Bobber struct {
+	Pos    world.IsometricCoordinate
+	Active bool
+	bobPos int
+}
+
+func (b *Bobber) bob() {
+	if b.bobPos >= len(bobPositions) {
+		b.bobPos = 0
+	}
+	b.Pos.Z = world.WaterLevel + bobPositions[b.bobPos]/10
+	b.bobPos++
+}
+
+// Scrap floats on its water tile until it expires
+type Scrap struct {
+	Coord   world.IsometricCoordinate
+	Type    world.ScrapType
+	Expires time.Time
+	// on the magnet, it won't expire until the reel is over
+	Hooked bool
+}
+
+type Foliage struct {
+	Pos  world.IsometricCoordinate
+	Type world.FoliageType
+}
+
+// Session is one game on one map: the player, the bobber, scrap washing up
+// and the reel, stepped a tick at a time by Controls. It draws nothing and
+// reads no devices, so a Recording of its Controls plays back the same
+// anywhere.
+type Session struct {
+	clock       *TickClock
+	actionQueue ActionQueue
+	events      *EventBus
+	profile     *Profile
+	seed        int64
+	generator   world.MapGenerator
+	biomes      *world.BiomeTable
+
+	// the finished map, swapped in once the intro has grown it
+	tiles     []*world.Tile
+	tileIndex map[world.IsometricCoordinate]*world.Tile
+	history   *world.GenLog
+	mapRadius int
+	islands   []world.IslandInfo
+	collision *world.CollisionWorld
+	introDone bool
+	// the map as the intro has grown it so far, shown until it's done
+	intro *world.GrowingMap
+
+	player  Player
+	bobber  Bobber
+	foliage []Foliage
+
+	scrapTiles map[world.IsometricCoordinate]*Scrap
+	director   *SpawnDirector
+	// the reel in progress, if any
+	minigame *ReelMinigame
+	// the recipe being put together, if any, and how far along it is
+	crafting      *Recipe
+	craftProgress float64
+
+	// this tick's controls, and commands issued for the next tick
+	controls Controls
+	pending  []Command
+	// every tick stepped is appended here when set
+	recording *Recording
+
+	// dropped on Stop
+	subscriptions []func()
+}
+
+func NewSession(seed int64, generator world.MapGenerator, biomes *world.BiomeTable, profile *Profile, events *EventBus) *Session {
+	clock := &TickClock{}
+	return &Session{
+		clock:       clock,
+		actionQueue: NewActionQueue(clock),
+		events:      events,
+		profile:     profile,
+		seed:        seed,
+		generator:   generator,
+		biomes:      biomes,
+		player:      Player{Heading: world.IsometricCoordinate{X: 1}},
+	}
+}
+
+func (s *Session) Clock() *TickClock {
+	return s.clock
+}
+
+func (s *Session) Profile() *Profile {
+	return s.profile
+}
+
+func (s *Session) Player() Player {
+	return s.player
+}
+
+func (s *Session) Bobber() Bobber {
+	return s.bobber
+}
+
+func (s *Session) Foliage() []Foliage {
+	return s.foliage
+}
+
+// Minigame is the reel in progress, or nil
+func (s *Session) Minigame() *ReelMinigame {
+	return s.minigame
+}
+
+// Crafting is the recipe being put together and how far along it is, 0 to
+// 1, or nil
+func (s *Session) Crafting() (*Recipe, float64) {
+	return s.crafting, s.craftProgress
+}
+
+func (s *Session) IntroDone() bool {
+	return s.introDone
+}
+
+// Map is the finished map, whether or not the intro is still growing it
+func (s *Session) Map() []*world.Tile {
+	return s.tiles
+}
+
+// Tiles is the map as it stands, the intro's until that's done
+func (s *Session) Tiles() []*world.Tile {
+	if s.intro != nil {
+		return s.intro.Tiles()
+	}
+	return s.tiles
+}
+
+// ScrapAt is the scrap on coord's tile, or nil
+func (s *Session) ScrapAt(coord world.IsometricCoordinate) *Scrap {
+	return s.scrapTiles[coord]
+}
+
+func (s *Session) TileAt(x, y float64) *world.Tile {
+	return s.tileIndex[world.IsometricCoordinate{X: x, Y: y}]
+}
+
+// Issue queues cmd to run at the start of the next Step, and to be recorded
+// with it
+func (s *Session) Issue(cmd Command) {
+	s.pending = append(s.pending, cmd)
+}
+
+// shoreTile is the land nearest a water tile, or nil if there's none
+func (s *Session) shoreTile(coord world.IsometricCoordinate) *world.Tile {
+	tile := s.TileAt(coord.X, coord.Y)
+	// walk back toward the shore one depth at a time
+	for tile != nil && tile.Water {
+		var closer *world.Tile
+		for _, adj := range world.AdjIsometric(world.IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y}) {
+			if next := s.TileAt(adj.X, adj.Y); next != nil && (!next.Water || next.Depth < tile.Depth) {
+				closer = next
+				break
+			}
+		}
+		tile = closer
+	}
+	return tile
+}
+
+// shoreScrapTable is the scrap table of the land nearest a water tile, so
+// what washes up depends on the biome it washes up against
+func (s *Session) shoreScrapTable(coord world.IsometricCoordinate) *world.WeightedTable[world.ScrapType] {
+	if tile := s.shoreTile(coord); tile != nil && tile.Biome != nil {
+		return tile.Biome.Scrap
+	}
+	return world.DefaultScrapTable
+}
+
+// scrapRichness is how much more often scrap turns up off the island
+// nearest a water tile
+func (s *Session) scrapRichness(coord world.IsometricCoordinate) float64 {
+	if tile := s.shoreTile(coord); tile != nil && tile.Island > 0 && tile.Island <= len(s.islands) {
+		return s.islands[tile.Island-1].ScrapRichness
+	}
+	return 1
+}
+
+func (s *Session) generateScrap() {
+	if !s.director.CanSpawn(len(s.Scrap())) {
+		return
+	}
+	// scrap only turns up where a full cast could reach it from shore
+	stats := s.profile.Stats()
+	emptyTiles := make([]world.IsometricCoordinate, 0)
+	for coord, scrap := range s.scrapTiles {
+		if tile := s.TileAt(coord.X, coord.Y); scrap == nil && tile != nil && float64(tile.Depth) <= stats.CastRange {
+			emptyTiles = append(emptyTiles, coord)
+		}
+	}
+	if len(emptyTiles) == 0 {
+		return
+	}
+	// map order is random, sort so a seeded run always picks the same tile
+	sort.Slice(emptyTiles, func(i, j int) bool {
+		if emptyTiles[i].X != emptyTiles[j].X {
+			return emptyTiles[i].X < emptyTiles[j].X
+		}
+		return emptyTiles[i].Y < emptyTiles[j].Y
+	})
+	var bobber *world.IsometricCoordinate
+	if s.bobber.Active {
+		bobber = &s.bobber.Pos
+	}
+	spawningCoord, ok := s.director.PickTile(emptyTiles, s.player.Pos, bobber, stats.DetectionRadius, s.scrapRichness)
+	if !ok {
+		return
+	}
+	// deeper water skews the roll toward the rare end of the table
+	depth := 1
+	if tile := s.TileAt(spawningCoord.X, spawningCoord.Y); tile != nil {
+		depth = tile.Depth
+	}
+	roll := math.Pow(rand.Float64(), 1/(1+float64(depth-1)*scrapDepthRarity))
+	scrapType := s.shoreScrapTable(spawningCoord).PickRoll(roll)
+	s.scrapTiles[spawningCoord] = &Scrap{
+		Coord:   spawningCoord,
+		Type:    scrapType,
+		Expires: s.clock.Now().Add(sampleTimeDuration(scrapMinLife, scrapMaxLife)),
+	}
+	Publish(s.events, ScrapSpawned{Coord: spawningCoord, Type: scrapType})
+}
+
+func (s *Session) despawnScrap(scrap *Scrap, reason DespawnReason) {
+	s.scrapTiles[scrap.Coord] = nil
+	Publish(s.events, ScrapDespawned{Coord: scrap.Coord, Type: scrap.Type, Reason: reason})
+}
+
+// Scrap is every scrap on the map, sorted by tile so anything done to them
+// in order happens the same way in a replay
+func (s *Session) Scrap() []*Scrap {
+	active := make([]*Scrap, 0)
+	for _, scrap := range s.scrapTiles {
+		if scrap != nil {
+			active = append(active, scrap)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Coord.X != active[j].Coord.X {
+			return active[i].Coord.X < active[j].Coord.X
+		}
+		return active[i].Coord.Y < active[j].Coord.Y
+	})
+	return active
+}
+
+func (s *Session) expireScrap() {
+	for _, scrap := range s.Scrap() {
+		if !s.clock.Now().Before(scrap.Expires) && !scrap.Hooked {
+			s.despawnScrap(scrap, DespawnExpired)
+		}
+	}
+}
+
+// NearestScrap is how far the magnet, on the bobber if it's out or else
+// with the player, is from the closest scrap
+func (s *Session) NearestScrap() (float64, bool) {
+	magnet := s.player.Pos
+	if s.bobber.Active {
+		magnet = s.bobber.Pos
+	}
+	nearest, found := math.Inf(1), false
+	for _, scrap := range s.Scrap() {
+		nearest = math.Min(nearest, math.Hypot(scrap.Coord.X-magnet.X, scrap.Coord.Y-magnet.Y))
+		found = true
+	}
+	return nearest, found
+}
+
+func (s *Session) generateScrapHook() error {
+	s.generateScrap()
+	nextTime := s.director.NextDelay()
+	s.actionQueue.Add(NewTimerAction(s.clock, s.generateScrapHook, s.clock.Now().Add(nextTime)))
+	return nil
+}
+
+func (s *Session) cast(charge float64) {
+	distance := castMinRange + charge*(s.profile.Stats().CastRange-castMinRange)
+	target := world.IsometricCoordinate{
+		X: world.TileCenter(s.player.Pos.X + s.player.Heading.X*distance),
+		Y: world.TileCenter(s.player.Pos.Y + s.player.Heading.Y*distance),
+		Z: world.WaterLevel,
+	}
+	if tile := s.TileAt(target.X, target.Y); tile == nil || !tile.Water {
+		Publish(s.events, CastMissed{Target: target})
+		return
+	}
+	s.bobber.Pos = target
+	s.bobber.Active = true
+}
+
+func (s *Session) reel() {
+	bobberTile := world.IsometricCoordinate{X: s.bobber.Pos.X, Y: s.bobber.Pos.Y, Z: world.WaterLevel}
+	scrap := s.scrapTiles[bobberTile]
+	if scrap == nil {
+		s.bobber.Active = false
+		return
+	}
+	scrap.Hooked = true
+	s.minigame = NewReelMinigame(s.clock, scrap.Type, s.profile.Stats())
+	reeling := func() bool { return s.controls.Held.Has(ActionReel) }
+	s.actionQueue.Add(s.minigame.Action(reeling, func(result ReelResult) error {
+		scrap.Hooked = false
+		s.minigame = nil
+		s.bobber.Active = false
+		if result == ReelCaught {
+			s.despawnScrap(scrap, DespawnCaught)
+			s.profile.Inventory.Add(scrap.Type, 1)
+		}
+		Publish(s.events, ReelFinished{Type: scrap.Type, Result: result})
+		return nil
+	}))
+}
+
+// UpgradeActions binds an action to each upgrade, in a fixed order so
+// buying two on one tick replays the same
+var UpgradeActions = []struct {
+	Action InputAction
+	ID     UpgradeID
+}{
+	{ActionBuyMagnet, UpgradeMagnet},
+	{ActionBuyCastRange, UpgradeCastRange},
+	{ActionBuyReelSpeed, UpgradeReelSpeed},
+	{ActionBuyDetection, UpgradeDetection},
+	{ActionBuyWalkSpeed, UpgradeWalkSpeed},
+}
+
+func (s *Session) buyUpgrade(id UpgradeID) {
+	if err := s.profile.Buy(id); err != nil {
+		Publish(s.events, UpgradeRefused{ID: id, Reason: err})
+		return
+	}
+	Publish(s.events, UpgradePurchased{ID: id, Level: s.profile.Upgrades[id]})
+}
+
+func (s *Session) craft(item ItemID) {
+	r := findRecipe(item)
+	if r == nil || s.crafting != nil || s.profile.Craft(r) != nil {
+		return
+	}
+	s.crafting = r
+	s.craftProgress = 0
+	s.actionQueue.Add(NewContinuousTimedAction(s.clock, func(percent float64, _ time.Duration) (bool, error) {
+		s.craftProgress = percent
+		return s.crafting == nil, nil
+	}, r.Time))
+	s.actionQueue.Add(NewTimerAction(s.clock, func() error {
+		if s.crafting == r {
+			s.finishCraft()
+		}
+		return nil
+	}, s.clock.Now().Add(r.Time)))
+}
+
+func (s *Session) finishCraft() {
+	r := s.crafting
+	s.crafting = nil
+	s.profile.Finish(r)
+	Publish(s.events, ItemCrafted{Item: r.Item})
+}
+
+func (s *Session) run(cmd Command) {
+	if cmd.Buy != "" {
+		s.buyUpgrade(cmd.Buy)
+	}
+	if cmd.Craft != "" {
+		s.craft(cmd.Craft)
+	}
+}
+
+func (s *Session) bobHook() error {
+	s.bobber.bob()
+	nextTime := s.clock.Now().Add(s.profile.Stats().BobDelay)
+	s.actionQueue.Add(NewTimerAction(s.clock, s.bobHook, nextTime))
+	return nil
+}
+
+// updateMapIntro grows the intro map up to how far through generation the
+// intro is, playing only the history steps since the last tick; interact
+// skips to the end
+func (s *Session) updateMapIntro(percentComplete float64, duration time.Duration) (bool, error) {
+	if s.introDone {
+		return true, nil
+	}
+	if s.controls.Pressed.Has(ActionInteract) {
+		return true, s.finishMapIntro()
+	}
+	s.intro.AdvanceTo(int(percentComplete * float64(s.history.Steps())))
+	return false, nil
+}
+
+func (s *Session) finishMapIntro() error {
+	if s.introDone {
+		return nil
+	}
+	s.introDone = true
+	s.intro = nil
+	Publish(s.events, TilesChanged{Tiles: s.tiles})
+	return s.generateScrapHook()
+}
+
+// Start generates the map and sets everything on it going, beginning with
+// the intro growing it
+func (s *Session) Start() error {
+	generated, err := s.generator.Generate(s.seed, world.DefaultMapParams)
+	if err != nil {
+		return fmt.Errorf("generating the map: %w", err)
+	}
+	s.biomes.Assign(generated.Tiles)
+	s.tiles = generated.Tiles
+	s.tileIndex = make(map[world.IsometricCoordinate]*world.Tile, len(s.tiles))
+	for _, tile := range s.tiles {
+		s.tileIndex[world.IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y}] = tile
+	}
+	s.history = generated.History
+	s.mapRadius = world.DefaultMapParams.MapRadius
+	s.islands = generated.Islands
+
+	s.scrapTiles = make(map[world.IsometricCoordinate]*Scrap)
+	for _, tile := range s.tiles {
+		if tile.Water && tile.Depth >= 1 && float64(tile.Depth) <= maxStats().CastRange {
+			s.scrapTiles[tile.Coord] = nil
+		}
+	}
+	s.director = NewSpawnDirector(s.clock)
+	s.subscriptions = append(s.subscriptions, Subscribe(s.events, s.director.Observe))
+
+	s.collision = world.NewCollisionWorld(s.TileAt)
+	spawn := generated.Spawn
+	s.player.Pos = world.IsometricCoordinate{X: spawn.X, Y: spawn.Y}
+	if ground := s.collision.GroundAt(s.player.Pos); ground != nil {
+		s.player.Pos.Z = ground.Coord.Z + playerStandHeight
+	}
+
+	s.foliage = make([]Foliage, 0)
+	for _, tile := range s.tiles {
+		if tile.Biome == nil || tile.Biome.Foliage.Len() == 0 || (tile.Coord.X == spawn.X && tile.Coord.Y == spawn.Y) {
+			continue
+		}
+		if rand.Float64() < tile.Biome.FoliageDensity {
+			foliageType := tile.Biome.Foliage.Pick()
+			s.foliage = append(s.foliage, Foliage{
+				Pos: world.IsometricCoordinate{
+					X: tile.Coord.X,
+					Y: tile.Coord.Y,
+					Z: tile.Coord.Z + 1.5,
+				},
+				Type: foliageType,
+			})
+			if foliageType == world.FOLIAGE_TREE {
+				s.collision.AddSolid(tile.Coord, treeCollisionRadius)
+			}
+		}
+	}
+
+	s.actionQueue.Add(s.update)
+	s.bobHook()
+	if s.history.Steps() == 0 {
+		return s.finishMapIntro()
+	}
+	s.introDone = false
+	s.intro = world.NewGrowingMap(s.history, s.mapRadius)
+	Publish(s.events, TilesChanged{Tiles: s.intro.Tiles()})
+	if _, err := s.updateMapIntro(0, mapIntroTime); err != nil {
+		return err
+	}
+	s.actionQueue.Add(NewContinuousTimedAction(s.clock, s.updateMapIntro, mapIntroTime))
+	s.actionQueue.Add(NewTimerAction(s.clock, s.finishMapIntro, s.clock.Now().Add(mapIntroTime)))
+	return nil
+}
+
+// update is the player's tick: walking, casting, reeling and buying
+func (s *Session) update() (bool, error) {
+	if !s.introDone {
+		return false, nil
+	}
+	s.expireScrap()
+
+	// the player stands still while reeling something in
+	move := math.Hypot(s.controls.MoveX, s.controls.MoveY)
+	if move > 0 && s.minigame == nil {
+		s.player.Heading = world.IsometricCoordinate{
+			X: s.controls.MoveX / move,
+			Y: s.controls.MoveY / move,
+		}
+		speed := s.profile.Stats().WalkSpeed * math.Min(1, move)
+		moveVec := world.IsometricCoordinate{
+			X: s.player.Heading.X * speed,
+			Y: s.player.Heading.Y * speed,
+		}
+		newPlayerPos, groundZ := s.collision.Move(s.player.Pos, moveVec, s.player.Pos.Z-playerStandHeight, playerCollisionRadius)
+		s.player.Pos = newPlayerPos
+		s.player.Pos.Z = groundZ + playerStandHeight
+	}
+
+	if s.bobber.Active {
+		s.player.CastCharge = 0
+	} else if s.controls.Held.Has(ActionCast) {
+		s.player.CastCharge = math.Min(1, s.player.CastCharge+TickDt/castChargeTime)
+	} else if s.player.CastCharge > 0 {
+		s.cast(s.player.CastCharge)
+		s.player.CastCharge = 0
+	}
+	if s.controls.Pressed.Has(ActionReel) && s.bobber.Active && s.minigame == nil {
+		s.reel()
+	}
+	for _, u := range UpgradeActions {
+		if s.controls.Pressed.Has(u.Action) {
+			s.buyUpgrade(u.ID)
+		}
+	}
+	return false, nil
+}
+
+// Step runs one tick on c and any commands issued since the last one
+func (s *Session) Step(c Controls) error {
+	c.Commands = append(c.Commands, s.pending...)
+	s.pending = nil
+	s.controls = c
+	for _, cmd := range c.Commands {
+		s.run(cmd)
+	}
+	if err := s.actionQueue.Update(); err != nil {
+		return err
+	}
+	if s.recording != nil {
+		s.recording.Ticks = append(s.recording.Ticks, c)
+	}
+	return nil
+}
+
+// Stop ends the session, finishing anything still being crafted since its
+// scrap is already spent
+func (s *Session) Stop() {
+	s.EndRecording()
+	if s.crafting != nil {
+		s.finishCraft()
+	}
+	for _, unsubscribe := range s.subscriptions {
+		unsubscribe()
+	}
+	s.subscriptions = nil
+}
+
+// Record appends every tick stepped from now on to rec, which starts from
+// the session's profile as it is now; call it before Start
+func (s *Session) Record(rec *Recording) {
+	rec.Profile = s.profile.Clone()
+	rec.Ticks = nil
+	s.recording = rec
+}
+
+// EndRecording stops recording, stamping the recording with the state the
+// session ended in
+func (s *Session) EndRecording() {
+	if s.recording == nil {
+		return
+	}
+	s.recording.FinalHash = s.StateHash()
+	s.recording = nil
+}
+
+func (s *Session) HashState(w io.Writer) {
+	hashFloats(w, float64(s.clock.Ticks()))
+	for _, tile := range s.Tiles() {
+		hashCoord(w, tile.Coord)
+	}
+	hashCoord(w, s.player.Pos)
+	hashCoord(w, s.player.Heading)
+	hashFloats(w, s.player.CastCharge)
+	for _, u := range Upgrades {
+		fmt.Fprintf(w, "%s%d", u.ID, s.profile.Upgrades[u.ID])
+	}
+	fmt.Fprint(w, FormatScrap(s.profile.Inventory))
+	for _, r := range Recipes {
+		fmt.Fprintf(w, "%s%d", r.Item, s.profile.Items[r.Item])
+	}
+	if s.minigame != nil {
+		hashFloats(w, s.minigame.Tension, s.minigame.Progress)
+	}
+	hashCoord(w, s.bobber.Pos)
+	fmt.Fprintf(w, "%v", s.bobber.Active)
+	for _, scrap := range s.Scrap() {
+		hashCoord(w, scrap.Coord)
+		fmt.Fprintf(w, "%d", scrap.Type)
+	}
+	if s.crafting != nil {
+		fmt.Fprint(w, s.crafting.Item)
+		hashFloats(w, s.craftProgress)
+	}
+}
+
+// StateHash sums up the whole simulation, for checking a replay ended where
+// its recording did
+func (s *Session) StateHash() string {
+	h := fnv.New64a()
+	s.HashState(h)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func sampleTimeDuration(minDur, maxDur time.Duration) time.Duration {
+	return time.Duration(rand.Intn(int((maxDur-minDur).Seconds())))*time.Second + minDur
+}
@@ -0,0 +1,162 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+type UpgradeID string
+
+const (
+	UpgradeMagnet    UpgradeID = "magnet"
+	UpgradeCastRange UpgradeID = "castRange"
+	UpgradeReelSpeed UpgradeID = "reelSpeed"
+	UpgradeDetection UpgradeID = "detection"
+	UpgradeWalkSpeed UpgradeID = "walkSpeed"
+
+	magnetBaseStrength     = 1.0
+	magnetStrengthPerLevel = 0.25
+	castRangePerLevel      = 1.0
+	reelSpeedPerLevel      = 0.2
+	// detection widens how far the bobber draws scrap in, and a more
+	// sensitive bobber bobs sooner
+	detectionPerLevel = 1.0
+	bobDelayPerLevel  = 0.15
+	walkSpeedPerLevel = 0.15
+)
+
+type Upgrade struct {
+	ID       UpgradeID
+	Name     string
+	MaxLevel int
+	// cost of level 1, level n costs n times as much
+	BaseCost map[world.ScrapType]int
+	// levels of other upgrades needed before the first level
+	Requires map[UpgradeID]int
+}
+
+// Upgrades is the upgrade tree, in menu order
+var Upgrades = []*Upgrade{
+	{
+		ID:       UpgradeMagnet,
+		Name:     "magnet strength",
+		MaxLevel: 4,
+		BaseCost: map[world.ScrapType]int{world.SCRAP_SCRAP: 3, world.SCRAP_ELEC: 1},
+	},
+	{
+		ID:       UpgradeCastRange,
+		Name:     "cast range",
+		MaxLevel: 3,
+		BaseCost: map[world.ScrapType]int{world.SCRAP_SCRAP: 2, world.SCRAP_WIRE: 2},
+	},
+	{
+		ID:       UpgradeReelSpeed,
+		Name:     "reel speed",
+		MaxLevel: 3,
+		BaseCost: map[world.ScrapType]int{world.SCRAP_WIRE: 3},
+		Requires: map[UpgradeID]int{UpgradeMagnet: 1},
+	},
+	{
+		ID:       UpgradeDetection,
+		Name:     "detection radius",
+		MaxLevel: 3,
+		BaseCost: map[world.ScrapType]int{world.SCRAP_ELEC: 2, world.SCRAP_WIRE: 1},
+		Requires: map[UpgradeID]int{UpgradeMagnet: 2},
+	},
+	{
+		ID:       UpgradeWalkSpeed,
+		Name:     "walk speed",
+		MaxLevel: 3,
+		BaseCost: map[world.ScrapType]int{world.SCRAP_SCRAP: 2},
+	},
+}
+
+func FindUpgrade(id UpgradeID) *Upgrade {
+	for _, u := range Upgrades {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (u *Upgrade) Cost(level int) map[world.ScrapType]int {
+	cost := make(map[world.ScrapType]int, len(u.BaseCost))
+	for scrapType, n := range u.BaseCost {
+		cost[scrapType] = n * level
+	}
+	return cost
+}
+
+// PlayerStats is everything upgrades change, read by player movement, the
+// bobber, the reel minigame and the scrap spawner
+type PlayerStats struct {
+	MagnetStrength float64
+	CastRange      float64
+	ReelSpeed      float64
+	// how far an active bobber pulls scrap spawns toward itself, in tiles
+	DetectionRadius float64
+	// tiles per tick
+	WalkSpeed float64
+	BobDelay  time.Duration
+}
+
+func statsFor(levels map[UpgradeID]int) PlayerStats {
+	return PlayerStats{
+		MagnetStrength:  magnetBaseStrength + magnetStrengthPerLevel*float64(levels[UpgradeMagnet]),
+		CastRange:       castRange + castRangePerLevel*float64(levels[UpgradeCastRange]),
+		ReelSpeed:       reelSpeed * (1 + reelSpeedPerLevel*float64(levels[UpgradeReelSpeed])),
+		DetectionRadius: scrapBobberFalloff + detectionPerLevel*float64(levels[UpgradeDetection]),
+		WalkSpeed:       walkSpeed * (1 + walkSpeedPerLevel*float64(levels[UpgradeWalkSpeed])),
+		BobDelay:        time.Duration(float64(bobDelay) * (1 - bobDelayPerLevel*float64(levels[UpgradeDetection]))),
+	}
+}
+
+// maxStats is the stats with every upgrade bought
+func maxStats() PlayerStats {
+	levels := make(map[UpgradeID]int)
+	for _, u := range Upgrades {
+		levels[u.ID] = u.MaxLevel
+	}
+	return statsFor(levels)
+}
+
+// CanBuy is nil if the next level of id is affordable and unlocked
+func (p *Profile) CanBuy(id UpgradeID) error {
+	u := FindUpgrade(id)
+	if u == nil {
+		return fmt.Errorf("no upgrade %q", id)
+	}
+	level := p.Upgrades[id] + 1
+	if level > u.MaxLevel {
+		return fmt.Errorf("%s is already maxed out", u.Name)
+	}
+	needs := make([]UpgradeID, 0, len(u.Requires))
+	for other := range u.Requires {
+		needs = append(needs, other)
+	}
+	sort.Slice(needs, func(i, j int) bool { return needs[i] < needs[j] })
+	for _, other := range needs {
+		if p.Upgrades[other] < u.Requires[other] {
+			return fmt.Errorf("%s needs %s level %d", u.Name, FindUpgrade(other).Name, u.Requires[other])
+		}
+	}
+	if cost := u.Cost(level); !p.Inventory.Has(cost) {
+		return fmt.Errorf("%s level %d costs %s, have %s", u.Name, level, FormatScrap(cost), FormatScrap(p.Inventory))
+	}
+	return nil
+}
+
+// Buy spends scrap from the profile's inventory on the next level of id
+func (p *Profile) Buy(id UpgradeID) error {
+	if err := p.CanBuy(id); err != nil {
+		return err
+	}
+	level := p.Upgrades[id] + 1
+	p.Inventory.Spend(FindUpgrade(id).Cost(level))
+	p.Upgrades[id] = level
+	return nil
+}
@@ -0,0 +1,20 @@
+package sim
+
+import "testing"
+
+func TestUpgradesReachWalkAndBob(t *testing.T) {
+	base := statsFor(nil)
+	if base.WalkSpeed != walkSpeed || base.BobDelay != bobDelay {
+		t.Errorf("without upgrades walk speed is %v and bob delay %v, want %v and %v", base.WalkSpeed, base.BobDelay, walkSpeed, bobDelay)
+	}
+	upgraded := statsFor(map[UpgradeID]int{UpgradeWalkSpeed: 2, UpgradeDetection: 2})
+	if upgraded.WalkSpeed <= base.WalkSpeed {
+		t.Errorf("walk speed 2 walks at %v, no faster than %v", upgraded.WalkSpeed, base.WalkSpeed)
+	}
+	if upgraded.BobDelay >= base.BobDelay {
+		t.Errorf("detection 2 bobs every %v, no sooner than %v", upgraded.BobDelay, base.BobDelay)
+	}
+	if max := maxStats(); max.BobDelay <= 0 {
+		t.Errorf("maxed detection bobs every %v", max.BobDelay)
+	}
+}
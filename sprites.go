@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+// SpriteProvider picks the image drawn for a tile. elapsed is the tilemap's
+// animation clock in seconds.
+type SpriteProvider interface {
+	Sprite(tile *world.Tile, tilemap *Tilemap, elapsed float64) *ebiten.Image
+}
+
+type staticSprite struct {
+	img *ebiten.Image
+}
+
+func (s *staticSprite) Sprite(tile *world.Tile, tilemap *Tilemap, elapsed float64) *ebiten.Image {
+	return s.img
+}
+
+type animatedSprite struct {
+	frames []*ebiten.Image
+	fps    float64
+}
+
+func (a *animatedSprite) Sprite(tile *world.Tile, tilemap *Tilemap, elapsed float64) *ebiten.Image {
+	// offset by coordinate so neighbouring tiles don't animate in lockstep
+	offset := int(coordHash(tile.Coord) % uint32(len(a.frames)))
+	frame := int(elapsed*a.fps) + offset
+	return a.frames[frame%len(a.frames)]
+}
+
+type variantSprite struct {
+	variants []SpriteProvider
+}
+
+func (v *variantSprite) Sprite(tile *world.Tile, tilemap *Tilemap, elapsed float64) *ebiten.Image {
+	variant := v.variants[coordHash(tile.Coord)%uint32(len(v.variants))]
+	return variant.Sprite(tile, tilemap, elapsed)
+}
+
+type transitionRule struct {
+	neighbors map[world.TileType]bool
+	sprite    SpriteProvider
+}
+
+// transitionSprite swaps in an edge sprite when any adjacent tile is one of
+// the rule's neighbour types, e.g. shoreline water next to sand
+type transitionSprite struct {
+	base  SpriteProvider
+	rules []transitionRule
+}
+
+func (t *transitionSprite) Sprite(tile *world.Tile, tilemap *Tilemap, elapsed float64) *ebiten.Image {
+	for _, rule := range t.rules {
+		for _, adj := range world.AdjIsometric(tile.Coord) {
+			if neighbor := tilemap.TileAt(adj.X, adj.Y); neighbor != nil && rule.neighbors[neighbor.Type] {
+				return rule.sprite.Sprite(tile, tilemap, elapsed)
+			}
+		}
+	}
+	return t.base.Sprite(tile, tilemap, elapsed)
+}
+
+func coordHash(c world.IsometricCoordinate) uint32 {
+	h := uint32(int32(c.X))*73856093 ^ uint32(int32(c.Y))*19349663
+	h ^= h >> 13
+	h *= 0x5bd1e995
+	h ^= h >> 15
+	return h
+}
+
+type spriteDef struct {
+	Frames      []int           `json:"frames"`
+	FPS         float64         `json:"fps"`
+	Variants    [][]int         `json:"variants"`
+	Transitions []transitionDef `json:"transitions"`
+}
+
+type transitionDef struct {
+	Neighbors []world.TileType `json:"neighbors"`
+	spriteDef
+}
+
+type tileDef struct {
+	spriteDef
+	Bob bool `json:"bob"`
+	// drawn below the tile down to its lower neighbours to fill cliff faces
+	Side *spriteDef `json:"side"`
+	// keyed by the direction the slope rises towards: -x, -y, +x or +y
+	Slopes map[string]spriteDef `json:"slopes"`
+}
+
+type TilesetConfig struct {
+	Sheet      string                     `json:"sheet"`
+	TileWidth  int                        `json:"tileWidth"`
+	TileHeight int                        `json:"tileHeight"`
+	Columns    int                        `json:"columns"`
+	Rows       int                        `json:"rows"`
+	Tiles      map[world.TileType]tileDef `json:"tiles"`
+}
+
+func LoadTilesetConfig(fsys fs.FS, filepath string) (*TilesetConfig, error) {
+	return NewAssetManager(fsys, "filesystem").TilesetConfig(filepath)
+}
+
+func (a *AssetManager) TilesetConfig(filepath string) (*TilesetConfig, error) {
+	raw, err := a.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	config := &TilesetConfig{}
+	if err := json.Unmarshal(raw, config); err != nil {
+		return nil, fmt.Errorf("parsing tileset %s: %w", filepath, err)
+	}
+	if err := config.checkSlopes(); err != nil {
+		return nil, fmt.Errorf("tileset %s: %w", filepath, err)
+	}
+	return config, nil
+}
+
+// checkSlopes makes sure land tiles, the ones with a side face, have a
+// sprite for every slope direction terrain classification can give them.
+// A missing one would draw flat under a player walking up the ramp.
+func (c *TilesetConfig) checkSlopes() error {
+	names := make([]string, 0, len(world.SlopeNames))
+	for name := range world.SlopeNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	types := make([]string, 0, len(c.Tiles))
+	for tType, def := range c.Tiles {
+		if def.Side != nil || len(def.Slopes) > 0 {
+			types = append(types, string(tType))
+		}
+	}
+	sort.Strings(types)
+	for _, tType := range types {
+		def := c.Tiles[world.TileType(tType)]
+		for _, name := range names {
+			if _, ok := def.Slopes[name]; !ok {
+				return fmt.Errorf("tile %s has no %s slope sprite", tType, name)
+			}
+		}
+	}
+	return nil
+}
+
+func frameSprite(frames []int, fps float64, sheet []*ebiten.Image) (SpriteProvider, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("sprite has no frames")
+	}
+	images := make([]*ebiten.Image, 0, len(frames))
+	for _, idx := range frames {
+		if idx < 0 || idx >= len(sheet) {
+			return nil, fmt.Errorf("%d out of range of loaded tiles (%d)", idx, len(sheet))
+		}
+		images = append(images, sheet[idx])
+	}
+	if len(images) == 1 || fps <= 0 {
+		return &staticSprite{images[0]}, nil
+	}
+	return &animatedSprite{frames: images, fps: fps}, nil
+}
+
+func (d *spriteDef) build(sheet []*ebiten.Image) (SpriteProvider, error) {
+	var base SpriteProvider
+	if len(d.Variants) > 0 {
+		variants := &variantSprite{}
+		for _, frames := range d.Variants {
+			variant, err := frameSprite(frames, d.FPS, sheet)
+			if err != nil {
+				return nil, err
+			}
+			variants.variants = append(variants.variants, variant)
+		}
+		base = variants
+	} else {
+		sprite, err := frameSprite(d.Frames, d.FPS, sheet)
+		if err != nil {
+			return nil, err
+		}
+		base = sprite
+	}
+	if len(d.Transitions) == 0 {
+		return base, nil
+	}
+	transitions := &transitionSprite{base: base}
+	for _, transition := range d.Transitions {
+		sprite, err := transition.spriteDef.build(sheet)
+		if err != nil {
+			return nil, err
+		}
+		rule := transitionRule{
+			neighbors: make(map[world.TileType]bool),
+			sprite:    sprite,
+		}
+		for _, neighbor := range transition.Neighbors {
+			rule.neighbors[neighbor] = true
+		}
+		transitions.rules = append(transitions.rules, rule)
+	}
+	return transitions, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/val-is/ebitengine-magnetism/world"
+)
+
+func TestTilesetSlopesComplete(t *testing.T) {
+	fsys, err := OpenAssets("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTilesetConfig(fsys, "tiles.json"); err != nil {
+		t.Errorf("embedded tileset: %v", err)
+	}
+}
+
+func TestEmbeddedBiomesInTileset(t *testing.T) {
+	fsys, err := OpenAssets("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadTilesetConfig(fsys, "tiles.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := NewAssetManager(fsys, "embedded resources")
+	raw, err := assets.ReadFile("biomes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	biomes, err := world.ParseBiomes(raw, "biomes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := biomes.CheckTiles(func(tType world.TileType) bool {
+		_, ok := config.Tiles[tType]
+		return ok
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTilesetMissingSlope(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tiles.json": {Data: []byte(`{"tiles": {"landTile": {
+			"frames": [0],
+			"side": {"frames": [3]},
+			"slopes": {"-x": {"frames": [18]}, "-y": {"frames": [27]}}
+		}}}`)},
+	}
+	_, err := LoadTilesetConfig(fsys, "tiles.json")
+	if err == nil || !strings.Contains(err.Error(), "+x") {
+		t.Errorf("expected a missing +x slope error, got %v", err)
+	}
+}
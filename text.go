@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+const (
+	uiFontPath = "fonts/goregular.ttf"
+
+	// point sizes at 72 dpi, so pixels on the logical screen
+	textSizeSmall = 20
+	textSizeBody  = 28
+	textSizeTitle = 96
+)
+
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextStyle is how DrawText lays out and decorates a block of text
+type TextStyle struct {
+	Face  font.Face
+	Color color.Color
+	// each line is aligned against the x passed to DrawText
+	Align TextAlign
+	// wrap at word boundaries past this many pixels, 0 never wraps
+	MaxWidth int
+	// nil for none
+	Outline color.Color
+	Shadow  color.Color
+}
+
+// Fonts are the faces every scene shares, loaded once per Game
+type Fonts struct {
+	Small, Body, Title font.Face
+}
+
+func LoadFonts(assets *AssetManager) *Fonts {
+	return &Fonts{
+		Small: uiFace(assets, textSizeSmall),
+		Body:  uiFace(assets, textSizeBody),
+		Title: uiFace(assets, textSizeTitle),
+	}
+}
+
+// uiFace loads the bundled TTF at size, falling back to a bitmap font so a
+// missing or broken font file still leaves the game readable
+func uiFace(assets *AssetManager, size float64) font.Face {
+	face, err := assets.Font(uiFontPath, size)
+	if err != nil {
+		log.Printf("loading font, using the bitmap fallback: %v", err)
+		return basicfont.Face7x13
+	}
+	return face
+}
+
+func (a *AssetManager) Font(filepath string, size float64) (font.Face, error) {
+	key := fmt.Sprintf("%s@%g", filepath, size)
+	if face, ok := a.faces[key]; ok {
+		return face, nil
+	}
+	raw, err := a.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := opentype.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing font %s: %w", filepath, err)
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parsing font %s: %w", filepath, err)
+	}
+	a.faces[key] = face
+	return face, nil
+}
+
+func lineHeight(face font.Face) int {
+	return face.Metrics().Height.Ceil()
+}
+
+func textWidth(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+// WrapText splits s into lines no wider than maxWidth, breaking between
+// words and at newlines. A single word wider than maxWidth gets a line to
+// itself.
+func WrapText(face font.Face, s string, maxWidth int) []string {
+	lines := make([]string, 0)
+	for _, paragraph := range strings.Split(s, "\n") {
+		if maxWidth <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line == "" {
+				line = word
+			} else if next := line + " " + word; textWidth(face, next) <= maxWidth {
+				line = next
+			} else {
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// decorationSize is how far outlines and shadows reach out from the glyphs,
+// scaled with the font so big text doesn't get a hairline
+func decorationSize(face font.Face) int {
+	return int(math.Max(1, math.Round(float64(lineHeight(face))/24)))
+}
+
+// MeasureText is the size of the box DrawText fills with s, decorations
+// included
+func MeasureText(s string, style TextStyle) (int, int) {
+	lines := WrapText(style.Face, s, style.MaxWidth)
+	w := 0
+	for _, line := range lines {
+		if lw := textWidth(style.Face, line); lw > w {
+			w = lw
+		}
+	}
+	h := len(lines) * lineHeight(style.Face)
+	if style.Outline != nil {
+		w += 2 * decorationSize(style.Face)
+		h += 2 * decorationSize(style.Face)
+	} else if style.Shadow != nil {
+		w += decorationSize(style.Face)
+		h += decorationSize(style.Face)
+	}
+	return w, h
+}
+
+// DrawText draws s with the top of its first line at y
+func DrawText(screen *ebiten.Image, s string, x, y int, style TextStyle) {
+	clr := style.Color
+	if clr == nil {
+		clr = color.White
+	}
+	d := decorationSize(style.Face)
+	ascent := style.Face.Metrics().Ascent.Ceil()
+	for i, line := range WrapText(style.Face, s, style.MaxWidth) {
+		lx := x
+		switch style.Align {
+		case AlignCenter:
+			lx -= textWidth(style.Face, line) / 2
+		case AlignRight:
+			lx -= textWidth(style.Face, line)
+		}
+		ly := y + ascent + i*lineHeight(style.Face)
+		if style.Shadow != nil {
+			text.Draw(screen, line, style.Face, lx+d, ly+d, style.Shadow)
+		}
+		if style.Outline != nil {
+			for dx := -d; dx <= d; dx += d {
+				for dy := -d; dy <= d; dy += d {
+					if dx != 0 || dy != 0 {
+						text.Draw(screen, line, style.Face, lx+dx, ly+dy, style.Outline)
+					}
+				}
+			}
+		}
+		text.Draw(screen, line, style.Face, lx, ly, clr)
+	}
+}
@@ -2,9 +2,13 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
 	"math"
+	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/val-is/ebitengine-magnetism/sim"
+	"github.com/val-is/ebitengine-magnetism/world"
 )
 
 const (
@@ -12,80 +16,191 @@ const (
 	tileHeight = 1920 / 7
 
 	tileSidePx = 9.2376 / 32 * tileHeight
-)
-
-type tileType string
 
-type Tile struct {
-	tileType tileType
-	coord    IsometricCoordinate
-	walkable bool
-}
-
-func (t *Tile) CollidesWith(c IsometricCoordinate) bool {
-	return t.coord.x-0.5 <= c.x+0.5 && c.x+0.5 <= t.coord.x+0.5 &&
-		t.coord.y-0.5 <= c.y+0.5 && c.y+0.5 <= t.coord.y+0.5
-}
+	// water darkens this much per tile out from shore, down to the floor
+	waterShadePerDepth = 0.05
+	waterShadeFloor    = 0.6
+)
 
 type Tilemap struct {
-	spritemap map[tileType]*ebiten.Image // TODO animated/custom sprite class support
-	tiles     []*Tile
+	spritemap map[world.TileType]SpriteProvider
+	sides     map[world.TileType]SpriteProvider
+	slopes    map[world.TileType]map[world.SlopeDir]SpriteProvider
+	bobbing   map[world.TileType]bool
+	tiles     []*world.Tile
+	tileIndex map[world.IsometricCoordinate]*world.Tile
     waterPeriod float64
+	elapsed   float64
+}
+
+// NewTilemap loads the tileset described by configPath straight from fsys
+func NewTilemap(fsys fs.FS, configPath string) (*Tilemap, error) {
+	return NewAssetManager(fsys, "filesystem").Tilemap(configPath)
 }
 
-func NewTilemap(filepath string, indexToType map[int]tileType) (*Tilemap, error) {
-	loadedTiles, err := LoadTiledSpritemap(filepath, 32, 32, 6, 9, 0, 0) // TODO hardcoded lol
+// Tilemap loads a tileset through a's cache, so its sheet hot reloads
+func (a *AssetManager) Tilemap(configPath string) (*Tilemap, error) {
+	config, err := a.TilesetConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	loadedTiles, err := a.Spritemap(config.Sheet, config.TileWidth, config.TileHeight, config.Columns, config.Rows, 0, 0)
 	if err != nil {
 		return nil, err
 	}
 	t := &Tilemap{
-		spritemap: make(map[tileType]*ebiten.Image),
-		tiles:     make([]*Tile, 0),
+		spritemap: make(map[world.TileType]SpriteProvider),
+		sides:     make(map[world.TileType]SpriteProvider),
+		slopes:    make(map[world.TileType]map[world.SlopeDir]SpriteProvider),
+		bobbing:   make(map[world.TileType]bool),
+		tiles:     make([]*world.Tile, 0),
+		tileIndex: make(map[world.IsometricCoordinate]*world.Tile),
 	}
-	for idx, tType := range indexToType {
-		if idx > len(loadedTiles) {
-			return nil, fmt.Errorf("%d out of range of loaded tiles (%d)", idx, len(loadedTiles))
+	for tType, def := range config.Tiles {
+		sprite, err := def.build(loadedTiles)
+		if err != nil {
+			return nil, fmt.Errorf("tile %s: %w", tType, err)
+		}
+		t.spritemap[tType] = sprite
+		t.bobbing[tType] = def.Bob
+		if def.Side != nil {
+			if t.sides[tType], err = def.Side.build(loadedTiles); err != nil {
+				return nil, fmt.Errorf("tile %s side: %w", tType, err)
+			}
+		}
+		t.slopes[tType] = make(map[world.SlopeDir]SpriteProvider)
+		for name, slopeDef := range def.Slopes {
+			dir, ok := world.SlopeNames[name]
+			if !ok {
+				return nil, fmt.Errorf("tile %s: unknown slope direction %q", tType, name)
+			}
+			if t.slopes[tType][dir], err = slopeDef.build(loadedTiles); err != nil {
+				return nil, fmt.Errorf("tile %s slope %s: %w", tType, name, err)
+			}
 		}
-		t.spritemap[tType] = loadedTiles[idx]
 	}
 	return t, nil
 }
 
+func (t *Tilemap) SetTiles(tiles []*world.Tile) {
+	// back to front, so nearer tiles and their cliff faces cover farther ones
+	t.tiles = append([]*world.Tile(nil), tiles...)
+	sort.SliceStable(t.tiles, func(i, j int) bool {
+		a, b := t.tiles[i].Coord, t.tiles[j].Coord
+		if a.X+a.Y != b.X+b.Y {
+			return a.X+a.Y < b.X+b.Y
+		}
+		return a.Z < b.Z
+	})
+	t.tileIndex = make(map[world.IsometricCoordinate]*world.Tile, len(tiles))
+	for _, tile := range tiles {
+		t.tileIndex[world.IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y}] = tile
+	}
+}
+
+// HasTile is true when the tileset has a sprite for tType
+func (t *Tilemap) HasTile(tType world.TileType) bool {
+	_, ok := t.spritemap[tType]
+	return ok
+}
+
+func (t *Tilemap) TileAt(x, y float64) *world.Tile {
+	return t.tileIndex[world.IsometricCoordinate{X: x, Y: y}]
+}
+
 func GetWaterOffset(posX, t float64) float64 {
     v := math.Cos(9.0/11.0 * (t+posX)) + 0.5*math.Cos(2.0/7.0 * (t+posX)) + 0.25*math.Cos(2.0/11.0 * (t+posX))
     return v * 0.1
 }
 
-func (t *Tilemap) Draw(screen *ebiten.Image, cameraPos IsometricCoordinate) {
+func drawTileImage(screen *ebiten.Image, view *Viewport, img *ebiten.Image, coord world.IsometricCoordinate, shade float64) {
+	screenCoord := view.WorldToScreen(coord)
+	w, h := img.Size()
 	drawOpt := ebiten.DrawImageOptions{}
+	drawOpt.GeoM.Scale(tileWidth*view.zoom/float64(w), tileWidth*view.zoom/float64(h))
+	drawOpt.GeoM.Translate(
+		screenCoord.x-tileWidth*view.zoom/2,
+		screenCoord.y-tileHeight*view.zoom/2,
+	)
+	if shade != 1 {
+		drawOpt.ColorM.Scale(shade, shade, shade, 1)
+	}
+	screen.DrawImage(img, &drawOpt)
+}
+
+func depthShade(tile *world.Tile) float64 {
+	if !tile.Water || tile.Depth <= 1 {
+		return 1
+	}
+	return math.Max(waterShadeFloor, 1-float64(tile.Depth-1)*waterShadePerDepth)
+}
+
+// sideFloor is how far down a tile's cliff face shows, the top of the lower
+// of its two neighbours nearer the camera
+func (t *Tilemap) sideFloor(tile *world.Tile) float64 {
+	floor := tile.Coord.Z
+	for _, adj := range []world.IsometricCoordinate{
+		{X: tile.Coord.X + 1, Y: tile.Coord.Y},
+		{X: tile.Coord.X, Y: tile.Coord.Y + 1},
+	} {
+		if neighbor := t.TileAt(adj.X, adj.Y); neighbor != nil {
+			floor = math.Min(floor, neighbor.Coord.Z)
+		} else {
+			floor = math.Min(floor, world.WaterLevel)
+		}
+	}
+	return floor
+}
+
+// Update advances water and animated tiles one tick; it's not done in Draw
+// so they hold still while the game is paused
+func (t *Tilemap) Update() {
     t.waterPeriod += 0.01
+	t.elapsed += sim.TickDt
+}
+
+func (t *Tilemap) Draw(screen *ebiten.Image, view *Viewport) {
+	margin := tileWidth * view.zoom
 	for _, tile := range t.tiles {
-		img, present := t.spritemap[tile.tileType]
+		sprite, present := t.spritemap[tile.Type]
 		if !present {
-			panic("sprite " + tile.tileType + " not set up!!!")
+			panic("sprite " + tile.Type + " not set up!!!")
+		}
+		// skip tiles whose whole column is off screen
+		top := view.WorldToScreen(tile.Coord)
+		bottom := view.WorldToScreen(world.IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y, Z: world.WaterLevel - world.HeightStep})
+		if top.x < -margin || top.x > view.width+margin || top.y > view.height+margin || bottom.y < -margin {
+			continue
 		}
+		if side, ok := t.sides[tile.Type]; ok {
+			img := side.Sprite(tile, t, t.elapsed)
+			for z := tile.Coord.Z - world.HeightStep; z > t.sideFloor(tile); z -= world.HeightStep {
+				drawTileImage(screen, view, img, world.IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y, Z: z}, 1)
+			}
+		}
+		img := sprite.Sprite(tile, t, t.elapsed)
         zOffset := 0.0
-        if tile.tileType == TILE_WATER {
-            zOffset = GetWaterOffset(tile.coord.x+tile.coord.y*0.5, t.waterPeriod)
+        if t.bobbing[tile.Type] {
+            zOffset = GetWaterOffset(tile.Coord.X+tile.Coord.Y*0.5, t.waterPeriod)
         }
-		screenCoord := iso2Screen(IsometricCoordinate{
-			x: tile.coord.x - cameraPos.x,
-			y: tile.coord.y - cameraPos.y,
-            z: tile.coord.z - cameraPos.z + float64(zOffset),
-		})
-		w, h := img.Size()
-		drawOpt.GeoM.Reset()
-		drawOpt.GeoM.Scale(tileWidth/float64(w), tileWidth/float64(h))
-		drawOpt.GeoM.Translate(
-			screenCoord.x+1920/2-tileWidth/2,
-			screenCoord.y+1080/2-tileHeight/2,
-		)
-		screen.DrawImage(img, &drawOpt)
+		drawTileImage(screen, view, img, world.IsometricCoordinate{
+			X: tile.Coord.X,
+			Y: tile.Coord.Y,
+            Z: tile.Coord.Z + float64(zOffset),
+		}, depthShade(tile))
+		// slope wedges sit on top of the tile's cube, rising to the neighbour
+		if slope, ok := t.slopes[tile.Type][tile.Slope]; ok {
+			drawTileImage(screen, view, slope.Sprite(tile, t, t.elapsed), world.IsometricCoordinate{
+				X: tile.Coord.X,
+				Y: tile.Coord.Y,
+				Z: tile.Coord.Z + world.HeightStep,
+			}, 1)
+		}
 	}
 }
 
-func (t *Tilemap) GetTilesAt(coordinate IsometricCoordinate) []*Tile {
-	tiles := make([]*Tile, 0)
+func (t *Tilemap) GetTilesAt(coordinate world.IsometricCoordinate) []*world.Tile {
+	tiles := make([]*world.Tile, 0)
 	for _, tile := range t.tiles {
 		if tile.CollidesWith(coordinate) {
 			tiles = append(tiles, tile)
@@ -94,7 +209,7 @@ func (t *Tilemap) GetTilesAt(coordinate IsometricCoordinate) []*Tile {
 	return tiles
 }
 
-func (t *Tilemap) GetClickedCoordinate(screenCoord ScreenCoordinate) IsometricCoordinate {
+func (t *Tilemap) GetClickedCoordinate(screenCoord ScreenCoordinate) world.IsometricCoordinate {
 	// ignores z val
-	return IsometricCoordinate{}
+	return world.IsometricCoordinate{}
 }
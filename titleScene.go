@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"image/color"
+
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/val-is/ebitengine-magnetism/sim"
 )
 
 type titleSceneImpl struct {
@@ -11,7 +14,7 @@ type titleSceneImpl struct {
 
 func (t *titleSceneImpl) Start() error {
     t.actionQueue.Add(func() (bool, error) {
-        if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+        if t.game.input.JustPressed(sim.ActionInteract) {
             if next, err := NewGameScene(t.game); err != nil {
                 return false, err
             } else {
@@ -32,6 +35,29 @@ func (t *titleSceneImpl) Update() error {
 }
 
 func (t *titleSceneImpl) Draw(screen *ebiten.Image) {
+    fonts := t.game.Fonts()
+    w, h := screen.Size()
+    DrawText(screen, "magnet fishing", w/2, h/3, TextStyle{
+        Face: fonts.Title,
+        Align: AlignCenter,
+        Color: color.RGBA{0xe0, 0xc0, 0x40, 0xff},
+        Shadow: color.RGBA{0x40, 0x20, 0x10, 0xff},
+    })
+    DrawText(screen, "fish scrap out of the sea and build a boat to escape the island", w/2, h/3+lineHeight(fonts.Title), TextStyle{
+        Face: fonts.Body,
+        Align: AlignCenter,
+        MaxWidth: w/2,
+    })
+    DrawText(screen, "press E or Enter to start", w/2, 2*h/3, TextStyle{
+        Face: fonts.Body,
+        Align: AlignCenter,
+        Outline: color.Black,
+    })
+    DrawText(screen, fmt.Sprintf("seed %d", t.game.seed), w-h/40, h-h/40-lineHeight(fonts.Small), TextStyle{
+        Face: fonts.Small,
+        Align: AlignRight,
+        Color: color.RGBA{0x80, 0x80, 0x80, 0xff},
+    })
 }
 
 func NewTitleScene(game *Game) (Scene, error) {
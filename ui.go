@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+const uiPad = 8
+
+var (
+	uiPanelColor    = color.RGBA{0x10, 0x10, 0x10, 0xc0}
+	uiButtonColor   = color.RGBA{0x30, 0x38, 0x40, 0xff}
+	uiHoverColor    = color.RGBA{0x40, 0x50, 0x60, 0xff}
+	uiPressedColor  = color.RGBA{0x20, 0x28, 0x30, 0xff}
+	uiDisabledColor = color.RGBA{0x28, 0x28, 0x28, 0xff}
+	uiFocusColor    = color.RGBA{0xe0, 0xc0, 0x40, 0xff}
+	uiAccentColor   = color.RGBA{0x50, 0x90, 0xc0, 0xff}
+	uiTextColor     = color.RGBA{0xf0, 0xf0, 0xf0, 0xff}
+	uiDimTextColor  = color.RGBA{0x80, 0x80, 0x80, 0xff}
+)
+
+// UI is an immediate mode widget layer. Each tick a scene calls Begin,
+// declares its widgets, which report clicks straight back, then End; the
+// widgets are drawn from that tick's declarations in Draw. It only reads
+// Input, never the devices directly.
+//
+// Clicks, drags and the wheel over any widget are consumed, and so are the
+// move and interact actions while Keyboard is set, so nothing underneath
+// the UI reacts to them too.
+type UI struct {
+	input *Input
+	fonts *Fonts
+	// move and interact drive focus instead of the game, for menus
+	Keyboard bool
+
+	// widgets are told apart by the order they're declared in
+	next   int
+	active int // holding the mouse down on, or -1
+	focus  int // keyboard focus, or -1
+	// focus movement requested this tick, widgets can take it first
+	nav       int
+	activate  bool
+	focusable int
+
+	blocking bool
+	building []func(screen *ebiten.Image)
+	frame    []func(screen *ebiten.Image)
+}
+
+func NewUI(input *Input, fonts *Fonts) *UI {
+	return &UI{input: input, fonts: fonts, active: -1, focus: -1}
+}
+
+func (u *UI) Begin() {
+	u.next = 0
+	u.focusable = 0
+	u.blocking = false
+	u.building = u.building[:0]
+	u.nav = 0
+	u.activate = false
+	if u.Keyboard {
+		if u.focus < 0 {
+			u.focus = 0
+		}
+		if u.input.JustPressed(sim.ActionMoveDown) {
+			u.nav++
+		}
+		if u.input.JustPressed(sim.ActionMoveUp) {
+			u.nav--
+		}
+		u.activate = u.input.JustPressed(sim.ActionInteract)
+	}
+}
+
+func (u *UI) End() {
+	if u.focusable > 0 && u.focus >= 0 {
+		u.focus = ((u.focus+u.nav)%u.focusable + u.focusable) % u.focusable
+	}
+	if u.blocking || u.active >= 0 {
+		u.input.Consume(sim.ActionClick, sim.ActionMoveToCursor)
+		u.input.ConsumeWheel()
+	}
+	if u.Keyboard {
+		u.input.Consume(sim.ActionMoveUp, sim.ActionMoveDown, sim.ActionMoveLeft, sim.ActionMoveRight, sim.ActionInteract)
+	}
+	// the click was just consumed, so ask the raw state whether it's still held
+	if !u.input.cur.Held.Has(sim.ActionClick) {
+		u.active = -1
+	}
+	u.frame = append(u.frame[:0], u.building...)
+}
+
+func (u *UI) Draw(screen *ebiten.Image) {
+	for _, draw := range u.frame {
+		draw(screen)
+	}
+}
+
+func (u *UI) cursor() image.Point {
+	x, y := u.input.Cursor()
+	return image.Pt(x, y)
+}
+
+// widget claims the next id and reports whether the cursor is over r, and
+// if it can take focus whether it has it
+func (u *UI) widget(r image.Rectangle, focusable bool) (id int, hover, focused bool) {
+	id = u.next
+	u.next++
+	hover = u.cursor().In(r)
+	if hover {
+		u.blocking = true
+	}
+	if focusable {
+		focused = u.focus == u.focusable
+		if u.Keyboard && hover && u.input.JustPressed(sim.ActionClick) {
+			u.focus = u.focusable
+		}
+		u.focusable++
+	}
+	return id, hover, focused
+}
+
+func (u *UI) draw(f func(screen *ebiten.Image)) {
+	u.building = append(u.building, f)
+}
+
+func fillRect(screen *ebiten.Image, r image.Rectangle, c color.Color) {
+	ebitenutil.DrawRect(screen, float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()), c)
+}
+
+func strokeRect(screen *ebiten.Image, r image.Rectangle, width int, c color.Color) {
+	fillRect(screen, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width), c)
+	fillRect(screen, image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y), c)
+	fillRect(screen, image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y), c)
+	fillRect(screen, image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+// Panel is a backdrop that soaks up clicks
+func (u *UI) Panel(r image.Rectangle) {
+	u.widget(r, false)
+	u.draw(func(screen *ebiten.Image) {
+		fillRect(screen, r, uiPanelColor)
+	})
+}
+
+func (u *UI) Label(s string, x, y int, style TextStyle) {
+	u.draw(func(screen *ebiten.Image) {
+		DrawText(screen, s, x, y, style)
+	})
+}
+
+// Button is true on the tick it's clicked, or activated while focused
+func (u *UI) Button(r image.Rectangle, label string, enabled bool) bool {
+	id, hover, focused := u.widget(r, true)
+	if hover && u.input.JustPressed(sim.ActionClick) {
+		u.active = id
+	}
+	pressed := u.active == id && hover
+	clicked := enabled && ((pressed && u.input.JustReleased(sim.ActionClick)) || (focused && u.activate))
+
+	bg, fg := uiButtonColor, color.Color(uiTextColor)
+	switch {
+	case !enabled:
+		bg, fg = uiDisabledColor, uiDimTextColor
+	case pressed && u.input.Pressed(sim.ActionClick):
+		bg = uiPressedColor
+	case hover:
+		bg = uiHoverColor
+	}
+	u.draw(func(screen *ebiten.Image) {
+		fillRect(screen, r, bg)
+		if focused {
+			strokeRect(screen, r, 2, uiFocusColor)
+		}
+		style := TextStyle{Face: u.fonts.Small, Color: fg, Align: AlignCenter, MaxWidth: r.Dx() - 2*uiPad}
+		_, h := MeasureText(label, style)
+		DrawText(screen, label, r.Min.X+r.Dx()/2, r.Min.Y+(r.Dy()-h)/2, style)
+	})
+	return clicked
+}
+
+// List shows items one per row and is true when the selection changes.
+// Up and down move the selection while it has focus, carrying on to the
+// next widget past either end.
+func (u *UI) List(r image.Rectangle, items []string, selected *int) bool {
+	_, hover, focused := u.widget(r, true)
+	rowH := lineHeight(u.fonts.Body) + uiPad
+	before := *selected
+	if hover && u.input.JustPressed(sim.ActionClick) {
+		if row := (u.cursor().Y - r.Min.Y) / rowH; row < len(items) {
+			*selected = row
+		}
+	}
+	if focused && u.nav != 0 {
+		if next := *selected + u.nav; next >= 0 && next < len(items) {
+			*selected = next
+			u.nav = 0
+		}
+	}
+	sel := *selected
+	u.draw(func(screen *ebiten.Image) {
+		fillRect(screen, r, uiButtonColor)
+		for i, item := range items {
+			row := image.Rect(r.Min.X, r.Min.Y+i*rowH, r.Max.X, r.Min.Y+(i+1)*rowH)
+			if row.Max.Y > r.Max.Y {
+				break
+			}
+			if i == sel {
+				fillRect(screen, row, uiHoverColor)
+			}
+			DrawText(screen, item, row.Min.X+uiPad, row.Min.Y+uiPad/2, TextStyle{Face: u.fonts.Body, Color: uiTextColor})
+		}
+		if focused {
+			strokeRect(screen, r, 2, uiFocusColor)
+		}
+	})
+	return sel != before
+}
+
+// Slider drags value between lo and hi, left and right nudge it by step
+// while focused. It's true when value changes.
+func (u *UI) Slider(r image.Rectangle, label string, value *float64, lo, hi, step float64) bool {
+	id, hover, focused := u.widget(r, true)
+	before := *value
+	if hover && u.input.JustPressed(sim.ActionClick) {
+		u.active = id
+	}
+	if u.active == id && u.input.Pressed(sim.ActionClick) {
+		*value = lo + (hi-lo)*float64(u.cursor().X-r.Min.X)/float64(r.Dx())
+	}
+	if focused && u.Keyboard {
+		if u.input.JustPressed(sim.ActionMoveRight) {
+			*value += step
+		}
+		if u.input.JustPressed(sim.ActionMoveLeft) {
+			*value -= step
+		}
+	}
+	*value = math.Max(lo, math.Min(hi, *value))
+	fill := (*value - lo) / (hi - lo)
+	text := fmt.Sprintf("%s  %.2g", label, *value)
+	u.draw(func(screen *ebiten.Image) {
+		fillRect(screen, r, uiButtonColor)
+		fillRect(screen, image.Rect(r.Min.X, r.Min.Y, r.Min.X+int(float64(r.Dx())*fill), r.Max.Y), uiAccentColor)
+		if focused {
+			strokeRect(screen, r, 2, uiFocusColor)
+		}
+		style := TextStyle{Face: u.fonts.Small, Color: uiTextColor, Align: AlignCenter, Shadow: color.Black}
+		_, h := MeasureText(text, style)
+		DrawText(screen, text, r.Min.X+r.Dx()/2, r.Min.Y+(r.Dy()-h)/2, style)
+	})
+	return *value != before
+}
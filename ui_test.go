@@ -0,0 +1,103 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"github.com/val-is/ebitengine-magnetism/sim"
+)
+
+// heldState is an InputState with actions held and the cursor at x, y
+func heldState(x, y int, actions ...sim.InputAction) InputState {
+	state := InputState{CursorX: x, CursorY: y}
+	for _, action := range actions {
+		state.Held.Set(action)
+	}
+	return state
+}
+
+func TestButtonClicksOnceOnRelease(t *testing.T) {
+	input, err := NewInput(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := NewUI(input, nil)
+	r := image.Rect(0, 0, 100, 40)
+
+	ticks := []struct {
+		state InputState
+		want  bool
+	}{
+		{heldState(50, 20), false},
+		{heldState(50, 20, sim.ActionClick), false},
+		{heldState(50, 20, sim.ActionClick), false},
+		{heldState(50, 20), true},
+		{heldState(50, 20), false},
+	}
+	clicks := 0
+	for i, tick := range ticks {
+		input.Push(tick.state)
+		ui.Begin()
+		clicked := ui.Button(r, "ok", true)
+		ui.End()
+		if clicked != tick.want {
+			t.Errorf("tick %d: clicked = %v, want %v", i, clicked, tick.want)
+		}
+		if clicked {
+			clicks++
+		}
+	}
+	if clicks != 1 {
+		t.Errorf("button clicked %d times, want 1", clicks)
+	}
+}
+
+func TestButtonIgnoresReleaseOffIt(t *testing.T) {
+	input, err := NewInput(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := NewUI(input, nil)
+	r := image.Rect(0, 0, 100, 40)
+
+	for i, state := range []InputState{
+		heldState(50, 20),
+		heldState(50, 20, sim.ActionClick),
+		heldState(200, 20, sim.ActionClick),
+		heldState(200, 20),
+		heldState(50, 20),
+	} {
+		input.Push(state)
+		ui.Begin()
+		if ui.Button(r, "ok", true) {
+			t.Errorf("tick %d: clicked after dragging off the button", i)
+		}
+		ui.End()
+	}
+}
+
+func TestSliderDragsWhileHeld(t *testing.T) {
+	input, err := NewInput(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := NewUI(input, nil)
+	r := image.Rect(0, 0, 100, 20)
+	value := 0.0
+
+	for _, state := range []InputState{
+		heldState(0, 10),
+		heldState(10, 10, sim.ActionClick),
+		heldState(50, 10, sim.ActionClick),
+		// off the slider but still dragging it
+		heldState(80, 50, sim.ActionClick),
+	} {
+		input.Push(state)
+		ui.Begin()
+		ui.Slider(r, "volume", &value, 0, 1, 0.1)
+		ui.End()
+	}
+	if value != 0.8 {
+		t.Errorf("value = %v after dragging, want 0.8", value)
+	}
+}
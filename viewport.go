@@ -0,0 +1,49 @@
+package main
+
+import "github.com/val-is/ebitengine-magnetism/world"
+
+// Viewport maps world coordinates onto the logical screen. All screen math
+// goes through it rather than assuming a fixed resolution.
+type Viewport struct {
+	pos           world.IsometricCoordinate
+	width, height float64
+	zoom          float64
+}
+
+func NewViewport(width, height int, zoom float64) *Viewport {
+	return &Viewport{
+		width:  float64(width),
+		height: float64(height),
+		zoom:   zoom,
+	}
+}
+
+func (v *Viewport) Center() ScreenCoordinate {
+	return ScreenCoordinate{v.width / 2, v.height / 2}
+}
+
+func (v *Viewport) WorldToScreen(i world.IsometricCoordinate) ScreenCoordinate {
+	screenCoord := iso2Screen(world.IsometricCoordinate{
+		X: i.X - v.pos.X,
+		Y: i.Y - v.pos.Y,
+		Z: i.Z - v.pos.Z,
+	})
+	center := v.Center()
+	return ScreenCoordinate{
+		x: screenCoord.x*v.zoom + center.x,
+		y: screenCoord.y*v.zoom + center.y,
+	}
+}
+
+// ScreenToWorld ignores height, the result lies on the z=0 plane
+func (v *Viewport) ScreenToWorld(s ScreenCoordinate) world.IsometricCoordinate {
+	center := v.Center()
+	offset := screen2Iso(ScreenCoordinate{
+		x: (s.x - center.x) / v.zoom,
+		y: (s.y - center.y) / v.zoom,
+	})
+	return world.IsometricCoordinate{
+		X: offset.X + v.pos.X,
+		Y: offset.Y + v.pos.Y,
+	}
+}
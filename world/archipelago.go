@@ -0,0 +1,276 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// MapParams tunes generation; generators ignore fields they have no use for
+type MapParams struct {
+	Islands          int
+	MinSize, MaxSize int
+	// closest two island origins may be, in tiles
+	Spacing   float64
+	MapRadius int
+}
+
+var DefaultMapParams = MapParams{
+	Islands:   4,
+	MinSize:   30,
+	MaxSize:   100,
+	Spacing:   24,
+	MapRadius: 60,
+}
+
+// IslandInfo describes one generated island; land tiles carry its ID
+type IslandInfo struct {
+	ID     int
+	Origin IsometricCoordinate
+	// land tiles, and the corners of the box around them
+	Size     int
+	Min, Max IsometricCoordinate
+	// water tiles touching the island
+	Shoreline int
+	// how many times the flood fill started over to land in the size range
+	Retries int
+	// multiplier on how often scrap turns up around the island, richer
+	// the farther it is from the spawn island
+	ScrapRichness float64
+}
+
+// how many random spots to try before giving up on fitting every island in
+const maxOriginAttempts = 1000
+
+func placeIslandOrigins(params MapParams) ([]IsometricCoordinate, error) {
+	// keep islands far enough in that their edges stay on the map
+	reach := float64(params.MapRadius) - math.Sqrt(float64(params.MaxSize)) - 2
+	origins := []IsometricCoordinate{{}}
+	for attempts := 0; len(origins) < params.Islands && attempts < maxOriginAttempts; attempts++ {
+		candidate := IsometricCoordinate{
+			X: math.Round((rand.Float64()*2 - 1) * reach),
+			Y: math.Round((rand.Float64()*2 - 1) * reach),
+		}
+		clear := true
+		for _, origin := range origins {
+			if math.Hypot(candidate.X-origin.X, candidate.Y-origin.Y) < params.Spacing {
+				clear = false
+				break
+			}
+		}
+		if clear {
+			origins = append(origins, candidate)
+		}
+	}
+	if len(origins) < params.Islands {
+		return nil, fmt.Errorf("only fit %d of %d islands %.0f tiles apart in radius %d", len(origins), params.Islands, params.Spacing, params.MapRadius)
+	}
+	return origins, nil
+}
+
+// generateArchipelago grows several islands over a wider sea. The first
+// island sits at the origin and holds the spawn point.
+func generateArchipelago(params MapParams) (GeneratedMap, error) {
+	origins, err := placeIslandOrigins(params)
+	if err != nil {
+		return GeneratedMap{}, err
+	}
+	land := make([]*Tile, 0)
+	taken := make(map[IsometricCoordinate]bool)
+	history := &GenLog{}
+	islands := make([]IslandInfo, 0, len(origins))
+	for idx, origin := range origins {
+		islandTiles, islandHistory, retries, err := generateIslandFloodFill(params.MinSize, params.MaxSize, origin)
+		if err != nil {
+			return GeneratedMap{}, fmt.Errorf("island %d: %w", idx+1, err)
+		}
+		info := IslandInfo{
+			ID:      idx + 1,
+			Origin:  origin,
+			Retries: retries,
+			Min:     IsometricCoordinate{math.Inf(1), math.Inf(1), 0},
+			Max:     IsometricCoordinate{math.Inf(-1), math.Inf(-1), 0},
+		}
+		placed := make(map[IsometricCoordinate]bool)
+		for _, tile := range islandTiles {
+			tile.Coord.X += origin.X
+			tile.Coord.Y += origin.Y
+			key := IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}
+			if taken[key] {
+				continue
+			}
+			taken[key] = true
+			tile.Island = info.ID
+			placed[key] = true
+			land = append(land, tile)
+			info.Size++
+			info.Min.X = math.Min(info.Min.X, tile.Coord.X)
+			info.Min.Y = math.Min(info.Min.Y, tile.Coord.Y)
+			info.Max.X = math.Max(info.Max.X, tile.Coord.X)
+			info.Max.Y = math.Max(info.Max.Y, tile.Coord.Y)
+		}
+		// replay the island's growth shifted out to its origin, leaving out
+		// tiles an earlier island already claimed
+		for step := 0; step < islandHistory.Steps(); step++ {
+			for _, event := range islandHistory.Step(step) {
+				coord := IsometricCoordinate{event.coord.X + origin.X, event.coord.Y + origin.Y, event.coord.Z}
+				if placed[IsometricCoordinate{coord.X, coord.Y, 0}] {
+					history.Add(coord, event.tileType)
+				}
+			}
+			history.EndStep()
+		}
+		islands = append(islands, info)
+	}
+
+	finalTiles := GenerateMapFromTiles(land, params.MapRadius)
+	byCoord := indexTiles(finalTiles)
+	finishIslands(finalTiles, byCoord, islands)
+	spawn := pickSpawn(finalTiles, byCoord, islands[0].ID)
+	return GeneratedMap{finalTiles, spawn, history, islands}, nil
+}
+
+func indexTiles(tiles []*Tile) map[IsometricCoordinate]*Tile {
+	byCoord := make(map[IsometricCoordinate]*Tile, len(tiles))
+	for _, tile := range tiles {
+		byCoord[IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}] = tile
+	}
+	return byCoord
+}
+
+// finishIslands counts each island's shoreline and rolls its scrap richness,
+// which grows with distance from the first (spawn) island
+func finishIslands(tiles []*Tile, byCoord map[IsometricCoordinate]*Tile, islands []IslandInfo) {
+	for _, tile := range tiles {
+		if !tile.Water {
+			continue
+		}
+		counted := make(map[int]bool)
+		for _, adj := range AdjIsometric(IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}) {
+			if neighbor, ok := byCoord[adj]; ok && neighbor.Island != 0 && !counted[neighbor.Island] {
+				counted[neighbor.Island] = true
+				islands[neighbor.Island-1].Shoreline++
+			}
+		}
+	}
+	if len(islands) == 0 {
+		return
+	}
+	home := islands[0].Origin
+	farthest := 1.0
+	for _, info := range islands {
+		farthest = math.Max(farthest, math.Hypot(info.Origin.X-home.X, info.Origin.Y-home.Y))
+	}
+	for idx := range islands {
+		dist := math.Hypot(islands[idx].Origin.X-home.X, islands[idx].Origin.Y-home.Y)
+		islands[idx].ScrapRichness = 0.75 + dist/farthest + rand.Float64()*0.25
+	}
+}
+
+// labelIslands numbers connected land, largest island first, for generators
+// that don't grow islands one at a time
+func labelIslands(tiles []*Tile, byCoord map[IsometricCoordinate]*Tile) []IslandInfo {
+	components := make([][]*Tile, 0)
+	seen := make(map[*Tile]bool)
+	for _, start := range tiles {
+		if start.Water || seen[start] {
+			continue
+		}
+		seen[start] = true
+		component := []*Tile{start}
+		for i := 0; i < len(component); i++ {
+			for _, adj := range AdjIsometric(IsometricCoordinate{component[i].Coord.X, component[i].Coord.Y, 0}) {
+				if neighbor, ok := byCoord[adj]; ok && !neighbor.Water && !seen[neighbor] {
+					seen[neighbor] = true
+					component = append(component, neighbor)
+				}
+			}
+		}
+		components = append(components, component)
+	}
+	sort.SliceStable(components, func(i, j int) bool {
+		return len(components[i]) > len(components[j])
+	})
+
+	islands := make([]IslandInfo, 0, len(components))
+	for idx, component := range components {
+		info := IslandInfo{
+			ID:   idx + 1,
+			Size: len(component),
+			Min:  IsometricCoordinate{math.Inf(1), math.Inf(1), 0},
+			Max:  IsometricCoordinate{math.Inf(-1), math.Inf(-1), 0},
+		}
+		for _, tile := range component {
+			tile.Island = info.ID
+			info.Origin.X += tile.Coord.X / float64(len(component))
+			info.Origin.Y += tile.Coord.Y / float64(len(component))
+			info.Min.X = math.Min(info.Min.X, tile.Coord.X)
+			info.Min.Y = math.Min(info.Min.Y, tile.Coord.Y)
+			info.Max.X = math.Max(info.Max.X, tile.Coord.X)
+			info.Max.Y = math.Max(info.Max.Y, tile.Coord.Y)
+		}
+		islands = append(islands, info)
+	}
+	return islands
+}
+
+// pickSpawn prefers high ground on the island, but only somewhere the player
+// can walk down to the water from, so there's always a shore to fish off
+func pickSpawn(tiles []*Tile, byCoord map[IsometricCoordinate]*Tile, island int) IsometricCoordinate {
+	candidates := make([]*Tile, 0)
+	for _, tile := range tiles {
+		if tile.Island == island {
+			candidates = append(candidates, tile)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Coord.Z > candidates[j].Coord.Z
+	})
+	for _, candidate := range candidates {
+		if reachableShoreline(candidate, byCoord) > 0 {
+			return IsometricCoordinate{candidate.Coord.X, candidate.Coord.Y, 0}
+		}
+	}
+	if len(candidates) > 0 {
+		return IsometricCoordinate{candidates[0].Coord.X, candidates[0].Coord.Y, 0}
+	}
+	return IsometricCoordinate{}
+}
+
+// reachableShoreline counts water tiles next to land the player could walk
+// to from start, going down freely but up only by slopes
+func reachableShoreline(start *Tile, byCoord map[IsometricCoordinate]*Tile) int {
+	visited := map[*Tile]bool{start: true}
+	shore := make(map[*Tile]bool)
+	queue := []*Tile{start}
+	for len(queue) > 0 {
+		tile := queue[0]
+		queue = queue[1:]
+		for _, n := range []struct {
+			dx, dy float64
+			slope  SlopeDir
+		}{
+			{-1, 0, SLOPE_UP_NEG_X},
+			{0, -1, SLOPE_UP_NEG_Y},
+			{1, 0, SLOPE_UP_POS_X},
+			{0, 1, SLOPE_UP_POS_Y},
+		} {
+			neighbor, ok := byCoord[IsometricCoordinate{tile.Coord.X + n.dx, tile.Coord.Y + n.dy, 0}]
+			if !ok || visited[neighbor] {
+				continue
+			}
+			if !neighbor.Walkable {
+				shore[neighbor] = true
+				continue
+			}
+			rise := neighbor.Coord.Z - tile.Coord.Z
+			if rise > maxStepHeight && !(tile.Slope == n.slope && rise <= HeightStep) {
+				continue
+			}
+			visited[neighbor] = true
+			queue = append(queue, neighbor)
+		}
+	}
+	return len(shore)
+}
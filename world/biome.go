@@ -0,0 +1,120 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	foliageTypeNames = map[string]FoliageType{
+		"grass": FOLIAGE_GRASS,
+		"tree":  FOLIAGE_TREE,
+	}
+	ScrapTypeNames = map[string]ScrapType{
+		"scrap": SCRAP_SCRAP,
+		"wire":  SCRAP_WIRE,
+		"elec":  SCRAP_ELEC,
+	}
+)
+
+// Biome picks a tile's type and what grows or washes up around it. Biomes
+// match on height, moisture, water depth and whether the tile is on the
+// shore.
+type Biome struct {
+	Name  string   `json:"name"`
+	Tile  TileType `json:"tile"`
+	Water bool     `json:"water"`
+
+	MinHeight   *float64 `json:"minHeight"`
+	MaxHeight   *float64 `json:"maxHeight"`
+	MinMoisture *float64 `json:"minMoisture"`
+	MaxMoisture *float64 `json:"maxMoisture"`
+	// water only, tiles out from the nearest land
+	MinDepth *float64 `json:"minDepth"`
+	MaxDepth *float64 `json:"maxDepth"`
+	// true for tiles next to the other of land/water, false for inland or
+	// open water
+	Shore *bool `json:"shore"`
+
+	FoliageDensity float64 `json:"foliageDensity"`
+	// relative weights by name
+	FoliageWeights map[string]float64 `json:"foliage"`
+	ScrapWeights   map[string]float64 `json:"scrap"`
+
+	Foliage *WeightedTable[FoliageType] `json:"-"`
+	Scrap   *WeightedTable[ScrapType]   `json:"-"`
+}
+
+type BiomeTable struct {
+	Biomes []*Biome `json:"biomes"`
+}
+
+// ParseBiomes reads a biome table from raw, the contents of filepath
+func ParseBiomes(raw []byte, filepath string) (*BiomeTable, error) {
+	table := &BiomeTable{}
+	if err := json.Unmarshal(raw, table); err != nil {
+		return nil, fmt.Errorf("parsing biomes %s: %w", filepath, err)
+	}
+	var err error
+	for _, biome := range table.Biomes {
+		if biome.Foliage, err = weightedTableFromNames(biome.FoliageWeights, foliageTypeNames, "foliage"); err != nil {
+			return nil, fmt.Errorf("biome %s: %w", biome.Name, err)
+		}
+		biome.Scrap = DefaultScrapTable
+		if len(biome.ScrapWeights) > 0 {
+			if biome.Scrap, err = weightedTableFromNames(biome.ScrapWeights, ScrapTypeNames, "scrap"); err != nil {
+				return nil, fmt.Errorf("biome %s: %w", biome.Name, err)
+			}
+		}
+	}
+	return table, nil
+}
+
+// CheckTiles makes sure every biome's tile is one known has a sprite for,
+// so a typo in biomes.json fails at load rather than when the tile draws
+func (t *BiomeTable) CheckTiles(known func(TileType) bool) error {
+	for _, biome := range t.Biomes {
+		if !known(biome.Tile) {
+			return fmt.Errorf("biome %s: tile %q isn't in the tileset", biome.Name, biome.Tile)
+		}
+	}
+	return nil
+}
+
+func inRange(v float64, min, max *float64) bool {
+	return (min == nil || v >= *min) && (max == nil || v <= *max)
+}
+
+func (b *Biome) matches(tile *Tile, moisture float64, shore bool) bool {
+	if b.Water != tile.Water || (b.Shore != nil && *b.Shore != shore) {
+		return false
+	}
+	if b.Water {
+		return inRange(float64(tile.Depth), b.MinDepth, b.MaxDepth)
+	}
+	return inRange(tile.Coord.Z, b.MinHeight, b.MaxHeight) && inRange(moisture, b.MinMoisture, b.MaxMoisture)
+}
+
+// Assign gives every tile the first biome in the table that matches it
+func (t *BiomeTable) Assign(tiles []*Tile) {
+	water := make(map[IsometricCoordinate]bool)
+	for _, tile := range tiles {
+		water[IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}] = tile.Water
+	}
+	for _, tile := range tiles {
+		moisture := getMoisture(tile.Coord.X, tile.Coord.Y)
+		shore := false
+		for _, adj := range AdjIsometric(IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}) {
+			if isWater, ok := water[adj]; ok && isWater != tile.Water {
+				shore = true
+			}
+		}
+		for _, biome := range t.Biomes {
+			if biome.matches(tile, moisture, shore) {
+				tile.Biome = biome
+				tile.Type = biome.Tile
+				break
+			}
+		}
+	}
+}
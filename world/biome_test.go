@@ -0,0 +1,21 @@
+package world
+
+import "testing"
+
+func TestCheckTiles(t *testing.T) {
+	table, err := ParseBiomes([]byte(`{"biomes": [
+		{"name": "sea", "tile": "waterTile", "water": true},
+		{"name": "land", "tile": "landTyle"}
+	]}`), "biomes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := map[TileType]bool{TILE_WATER: true, TILE_LAND: true}
+	if err := table.CheckTiles(func(tType TileType) bool { return known[tType] }); err == nil {
+		t.Errorf("expected an error for the misspelled landTyle")
+	}
+	known["landTyle"] = true
+	if err := table.CheckTiles(func(tType TileType) bool { return known[tType] }); err != nil {
+		t.Errorf("every tile is known: %v", err)
+	}
+}
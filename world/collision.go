@@ -0,0 +1,88 @@
+package world
+
+import "math"
+
+// tallest rise the player can walk up without a slope, less than a whole
+// height step so cliffs block
+const maxStepHeight = HeightStep * 0.5
+
+type solidCircle struct {
+	center IsometricCoordinate
+	radius float64
+}
+
+// CollisionWorld answers whether a body can stand somewhere, against the
+// walkable ground and any solid objects placed on it
+type CollisionWorld struct {
+	// the tile at whole coordinates x, y, or nil
+	tileAt func(x, y float64) *Tile
+	solids map[IsometricCoordinate][]solidCircle
+}
+
+func NewCollisionWorld(tileAt func(x, y float64) *Tile) *CollisionWorld {
+	return &CollisionWorld{
+		tileAt: tileAt,
+		solids: make(map[IsometricCoordinate][]solidCircle),
+	}
+}
+
+func tileKey(x, y float64) IsometricCoordinate {
+	return IsometricCoordinate{TileCenter(x), TileCenter(y), 0}
+}
+
+func (c *CollisionWorld) AddSolid(center IsometricCoordinate, radius float64) {
+	key := tileKey(center.X, center.Y)
+	c.solids[key] = append(c.solids[key], solidCircle{center, radius})
+}
+
+// GroundAt is the walkable tile under pos, if any
+func (c *CollisionWorld) GroundAt(pos IsometricCoordinate) *Tile {
+	tile := c.tileAt(TileCenter(pos.X), TileCenter(pos.Y))
+	if tile == nil || !tile.Walkable {
+		return nil
+	}
+	return tile
+}
+
+// Blocked reports whether a body of radius standing at height z can't be at
+// pos. Every corner of its footprint needs ground no more than a step above
+// z, and it can't overlap a solid.
+func (c *CollisionWorld) Blocked(pos IsometricCoordinate, z, radius float64) bool {
+	for _, corner := range [][2]float64{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+		ground := c.GroundAt(IsometricCoordinate{
+			X: pos.X + corner[0]*radius,
+			Y: pos.Y + corner[1]*radius,
+		})
+		if ground == nil || ground.HeightAt(pos.X+corner[0]*radius, pos.Y+corner[1]*radius)-z > maxStepHeight {
+			return true
+		}
+	}
+	for dx := -1.0; dx <= 1; dx++ {
+		for dy := -1.0; dy <= 1; dy++ {
+			key := tileKey(pos.X+dx, pos.Y+dy)
+			for _, solid := range c.solids[key] {
+				if math.Hypot(pos.X-solid.center.X, pos.Y-solid.center.Y) < solid.radius+radius {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Move slides pos by delta one axis at a time, so running diagonally into a
+// wall keeps the component along it. z is the height of the ground the body
+// is on; the returned position has the new ground's height.
+func (c *CollisionWorld) Move(pos, delta IsometricCoordinate, z, radius float64) (IsometricCoordinate, float64) {
+	next := pos
+	if delta.X != 0 && !c.Blocked(IsometricCoordinate{X: next.X + delta.X, Y: next.Y}, z, radius) {
+		next.X += delta.X
+	}
+	if delta.Y != 0 && !c.Blocked(IsometricCoordinate{X: next.X, Y: next.Y + delta.Y}, z, radius) {
+		next.Y += delta.Y
+	}
+	if ground := c.GroundAt(next); ground != nil {
+		z = ground.HeightAt(next.X, next.Y)
+	}
+	return next, z
+}
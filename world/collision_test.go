@@ -0,0 +1,167 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+const testRadius = 0.2
+
+// testGround builds a CollisionWorld from rows of heights, x across and y
+// down. '~' is water and anything else not a digit is off the map.
+func testGround(rows ...string) (*CollisionWorld, map[IsometricCoordinate]*Tile) {
+	tiles := make(map[IsometricCoordinate]*Tile)
+	for y, row := range rows {
+		for x, c := range row {
+			key := IsometricCoordinate{X: float64(x), Y: float64(y)}
+			coord := key
+			switch {
+			case c == '~':
+				coord.Z = WaterLevel
+				tiles[key] = &Tile{Type: TILE_WATER, Coord: coord, Water: true}
+			case c >= '0' && c <= '9':
+				coord.Z = float64(c - '0')
+				tiles[key] = &Tile{Type: TILE_LAND, Coord: coord, Walkable: true}
+			}
+		}
+	}
+	collision := NewCollisionWorld(func(x, y float64) *Tile {
+		return tiles[IsometricCoordinate{X: x, Y: y}]
+	})
+	return collision, tiles
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBlockedByWaterAndEdges(t *testing.T) {
+	c, _ := testGround(
+		"111~",
+		"111 ",
+	)
+	for _, test := range []struct {
+		x, y    float64
+		blocked bool
+	}{
+		{1, 0, false},
+		{1.7, 0.5, false},
+		// footprint reaches over the water
+		{2.4, 0, true},
+		// and off the map
+		{2.4, 1, true},
+		{-0.4, 0, true},
+		{1, 1.4, true},
+	} {
+		if got := c.Blocked(IsometricCoordinate{X: test.x, Y: test.y}, 1, testRadius); got != test.blocked {
+			t.Errorf("Blocked(%v, %v) = %v, want %v", test.x, test.y, got, test.blocked)
+		}
+	}
+}
+
+func TestBlockedBySolids(t *testing.T) {
+	c, _ := testGround(
+		"111",
+		"111",
+	)
+	c.AddSolid(IsometricCoordinate{X: 1, Y: 1}, 0.25)
+	if !c.Blocked(IsometricCoordinate{X: 1.3, Y: 1}, 1, testRadius) {
+		t.Errorf("standing in a tree isn't blocked")
+	}
+	if c.Blocked(IsometricCoordinate{X: 0.5, Y: 0.5}, 1, testRadius) {
+		t.Errorf("standing clear of a tree is blocked")
+	}
+}
+
+func TestStepHeight(t *testing.T) {
+	c, tiles := testGround("113")
+	// a rise within maxStepHeight is a step up
+	tiles[IsometricCoordinate{X: 1}].Coord.Z = 1 + maxStepHeight
+	pos, z := c.Move(IsometricCoordinate{}, IsometricCoordinate{X: 1}, 1, testRadius)
+	if pos.X != 1 || !near(z, 1+maxStepHeight) {
+		t.Errorf("step up moved to %v at height %v, want x 1 at %v", pos, z, 1+maxStepHeight)
+	}
+	// a whole height step and more is a cliff
+	pos, z = c.Move(pos, IsometricCoordinate{X: 1}, z, testRadius)
+	if pos.X != 1 || !near(z, 1+maxStepHeight) {
+		t.Errorf("climbed a cliff to %v at height %v", pos, z)
+	}
+	// walking off it down again is fine
+	pos, z = c.Move(pos, IsometricCoordinate{X: -1}, z, testRadius)
+	if pos.X != 0 || z != 1 {
+		t.Errorf("stepping down moved to %v at height %v, want x 0 at 1", pos, z)
+	}
+}
+
+func TestMoveSlidesAlongWalls(t *testing.T) {
+	c, _ := testGround(
+		"111",
+		"111",
+		"~~~",
+	)
+	start := IsometricCoordinate{X: 1, Y: 1}
+	pos, _ := c.Move(start, IsometricCoordinate{X: 0.3, Y: 0.5}, 1, testRadius)
+	if !near(pos.X, 1.3) || pos.Y != 1 {
+		t.Errorf("moving diagonally into the water ended at %v, want to slide to (1.3, 1)", pos)
+	}
+	pos, _ = c.Move(start, IsometricCoordinate{X: 1.5, Y: -0.3}, 1, testRadius)
+	if !near(pos.Y, 0.7) || pos.X != 1 {
+		t.Errorf("moving diagonally off the east edge ended at %v, want to slide to (1, 0.7)", pos)
+	}
+}
+
+func TestSlopes(t *testing.T) {
+	c, tiles := testGround("12")
+	low := tiles[IsometricCoordinate{}]
+
+	// without a slope the next level up is a cliff
+	pos, _ := c.Move(IsometricCoordinate{}, IsometricCoordinate{X: 0.9}, 1, testRadius)
+	if pos.X != 0 {
+		t.Errorf("walked up a cliff to %v", pos)
+	}
+
+	low.Slope = SLOPE_UP_POS_X
+	if z := low.HeightAt(0, 0); z != 1.5 {
+		t.Errorf("slope is %v high at its centre, want 1.5", z)
+	}
+	if z := low.HeightAt(0.5, 0); z != 2 {
+		t.Errorf("slope is %v high at its top edge, want 2", z)
+	}
+	pos, z := c.Move(IsometricCoordinate{}, IsometricCoordinate{X: 0.9}, low.HeightAt(0, 0), testRadius)
+	if !near(pos.X, 0.9) || z != 2 {
+		t.Errorf("walking up the slope ended at %v height %v, want x 0.9 at 2", pos, z)
+	}
+
+	// classifyTerrain finds the same slope on its own
+	low.Slope = SLOPE_NONE
+	classifyTerrain([]*Tile{low, tiles[IsometricCoordinate{X: 1}]})
+	if low.Slope != SLOPE_UP_POS_X {
+		t.Errorf("classifyTerrain gave slope %v, want SLOPE_UP_POS_X", low.Slope)
+	}
+}
+
+func TestGroundAtHalfTileBoundary(t *testing.T) {
+	tiles := make(map[IsometricCoordinate]*Tile)
+	for x := -2.0; x <= 1; x++ {
+		for y := -2.0; y <= 1; y++ {
+			tiles[IsometricCoordinate{X: x, Y: y}] = &Tile{Type: TILE_LAND, Coord: IsometricCoordinate{X: x, Y: y, Z: 1}, Walkable: true}
+		}
+	}
+	c := NewCollisionWorld(func(x, y float64) *Tile {
+		return tiles[IsometricCoordinate{X: x, Y: y}]
+	})
+	for _, v := range []float64{-1.5, -0.5, -0.49, 0, 0.5, -0.51} {
+		pos := IsometricCoordinate{X: v, Y: v}
+		ground := c.GroundAt(pos)
+		if ground == nil {
+			t.Errorf("no ground at %v", v)
+			continue
+		}
+		if !ground.CollidesWith(pos) {
+			t.Errorf("GroundAt(%v) picked the tile at %v, whose footprint doesn't hold it", v, ground.Coord)
+		}
+	}
+	if ground := c.GroundAt(IsometricCoordinate{X: -0.5, Y: -0.5}); ground == nil || ground.Coord.X != 0 || ground.Coord.Y != 0 {
+		t.Errorf("GroundAt(-0.5, -0.5) should be the tile at 0, 0")
+	}
+}
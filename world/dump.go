@@ -0,0 +1,170 @@
+package world
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const dumpTilePx = 4
+
+var dumpTileColors = map[TileType]color.RGBA{
+	TILE_LAND:          {0x5a, 0xa8, 0x3c, 0xff},
+	TILE_SAND:          {0xe0, 0xc8, 0x80, 0xff},
+	TILE_WATER:         {0x2c, 0x6c, 0xc8, 0xff},
+	"beachTile":        {0xf0, 0xd8, 0x90, 0xff},
+	"meadowTile":       {0x8c, 0xc8, 0x50, 0xff},
+	"forestTile":       {0x28, 0x78, 0x30, 0xff},
+	"rockyTile":        {0x90, 0x90, 0x90, 0xff},
+	"shallowWaterTile": {0x48, 0x98, 0xe0, 0xff},
+	"deepWaterTile":    {0x18, 0x40, 0x90, 0xff},
+}
+
+// dumpColor falls back to a colour hashed from the name so new tile types
+// still show up distinctly
+func dumpColor(t TileType) color.RGBA {
+	if c, ok := dumpTileColors[t]; ok {
+		return c
+	}
+	h := fnv.New32a()
+	h.Write([]byte(t))
+	v := h.Sum32()
+	return color.RGBA{uint8(v), uint8(v >> 8), uint8(v >> 16), 0xff}
+}
+
+// mapImage draws tiles top down, x across and y down, one dumpTilePx
+// square per tile over a square of the given radius filled with bg
+func mapImage(tiles []*Tile, radius int, bg color.RGBA, colorOf func(*Tile) color.RGBA) *image.RGBA {
+	size := 2 * radius * dumpTilePx
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bg.R, bg.G, bg.B, bg.A
+	}
+	for _, tile := range tiles {
+		c := colorOf(tile)
+		px := (int(tile.Coord.X) + radius) * dumpTilePx
+		py := (int(tile.Coord.Y) + radius) * dumpTilePx
+		for dx := 0; dx < dumpTilePx; dx++ {
+			for dy := 0; dy < dumpTilePx; dy++ {
+				img.SetRGBA(px+dx, py+dy, c)
+			}
+		}
+	}
+	return img
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// DumpMapGeneration runs a generator without a window and writes what it
+// made into dir: a frame per generation step, the final height and tile type
+// maps, coloured by biomes, and stats.txt with per island numbers for tuning
+func DumpMapGeneration(biomes *BiomeTable, name string, gen MapGenerator, seed int64, params MapParams, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	world, err := gen.Generate(seed, params)
+	if err != nil {
+		return fmt.Errorf("generating %s seed %d: %w", name, seed, err)
+	}
+	radius := params.MapRadius
+
+	water := dumpColor(TILE_WATER)
+	for idx := 0; idx < world.History.Steps(); idx++ {
+		img := mapImage(world.History.Replay(idx), radius, water, func(t *Tile) color.RGBA { return dumpColor(t.Type) })
+		if err := writePNG(filepath.Join(dir, fmt.Sprintf("step_%04d.png", idx)), img); err != nil {
+			return err
+		}
+	}
+
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	maxDepth := 1
+	for _, tile := range world.Tiles {
+		if tile.Water {
+			if tile.Depth > maxDepth {
+				maxDepth = tile.Depth
+			}
+			continue
+		}
+		minZ, maxZ = math.Min(minZ, tile.Coord.Z), math.Max(maxZ, tile.Coord.Z)
+	}
+	// land in greys by height, water in blues by depth
+	heights := mapImage(world.Tiles, radius, color.RGBA{0, 0, 0, 0xff}, func(t *Tile) color.RGBA {
+		if t.Water {
+			v := uint8(0xe0 - 0xc0*float64(t.Depth)/float64(maxDepth))
+			return color.RGBA{0, v / 2, v, 0xff}
+		}
+		v := uint8(0x40)
+		if maxZ > minZ {
+			v += uint8(0xbf * (t.Coord.Z - minZ) / (maxZ - minZ))
+		}
+		return color.RGBA{v, v, v, 0xff}
+	})
+	if err := writePNG(filepath.Join(dir, "height.png"), heights); err != nil {
+		return err
+	}
+
+	biomes.Assign(world.Tiles)
+	types := mapImage(world.Tiles, radius, water, func(t *Tile) color.RGBA {
+		if t.Coord.X == world.Spawn.X && t.Coord.Y == world.Spawn.Y {
+			return color.RGBA{0xff, 0, 0, 0xff}
+		}
+		return dumpColor(t.Type)
+	})
+	if err := writePNG(filepath.Join(dir, "tiles.png"), types); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "stats.txt"))
+	if err != nil {
+		return err
+	}
+	writeMapStats(io.MultiWriter(f, os.Stdout), name, seed, world)
+	return f.Close()
+}
+
+func writeMapStats(w io.Writer, name string, seed int64, world GeneratedMap) {
+	land := 0
+	counts := make(map[TileType]int)
+	for _, tile := range world.Tiles {
+		counts[tile.Type]++
+		if !tile.Water {
+			land++
+		}
+	}
+	fmt.Fprintf(w, "generator %s seed %d\n", name, seed)
+	fmt.Fprintf(w, "tiles %d land %d steps %d spawn %v\n", len(world.Tiles), land, world.History.Steps(), world.Spawn)
+
+	names := make([]string, 0, len(counts))
+	for t := range counts {
+		names = append(names, string(t))
+	}
+	sort.Strings(names)
+	for _, t := range names {
+		fmt.Fprintf(w, "  %-18s %d\n", t, counts[TileType(t)])
+	}
+
+	fmt.Fprintf(w, "islands %d\n", len(world.Islands))
+	for _, info := range world.Islands {
+		fmt.Fprintf(w, "  #%d size %d origin (%.0f, %.0f) bounds (%.0f, %.0f)-(%.0f, %.0f) shoreline %d retries %d richness %.2f\n",
+			info.ID, info.Size, info.Origin.X, info.Origin.Y,
+			info.Min.X, info.Min.Y, info.Max.X, info.Max.Y,
+			info.Shoreline, info.Retries, info.ScrapRichness)
+	}
+}
@@ -0,0 +1,216 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultMapGenerator = "archipelago"
+
+	cellularFillChance = 0.5
+	cellularIterations = 5
+	// a cell turns to land with at least this many of its 8 neighbours land
+	cellularBirth = 5
+	// and stays land with at least this many
+	cellularSurvive = 4
+	// how many seeds past the one asked for to try before giving up
+	cellularReseeds = 10
+)
+
+var errNoLand = errors.New("generated no land")
+
+// GeneratedMap is a finished map: every tile, water included, where the
+// player starts, and how the land grew for the intro animation
+type GeneratedMap struct {
+	Tiles   []*Tile
+	Spawn   IsometricCoordinate
+	History *GenLog
+	Islands []IslandInfo
+}
+
+// MapGenerator builds a whole map from a seed. The same seed and params
+// always give the same map.
+type MapGenerator interface {
+	Generate(seed int64, params MapParams) (GeneratedMap, error)
+}
+
+var mapGenerators = map[string]MapGenerator{
+	"archipelago": archipelagoGenerator{},
+	"floodfill":   floodFillGenerator{},
+	"perlin":      perlinGenerator{},
+	"cellular":    cellularGenerator{},
+}
+
+func MapGeneratorNames() string {
+	names := make([]string, 0, len(mapGenerators))
+	for name := range mapGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func LookupMapGenerator(name string) (MapGenerator, error) {
+	if name == "" {
+		name = DefaultMapGenerator
+	}
+	gen, ok := mapGenerators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown map generator %q, expected one of %s", name, MapGeneratorNames())
+	}
+	return gen, nil
+}
+
+// archipelagoGenerator grows several flood filled islands over a wide sea
+type archipelagoGenerator struct{}
+
+func (archipelagoGenerator) Generate(seed int64, params MapParams) (GeneratedMap, error) {
+	Seed(seed)
+	return generateArchipelago(params)
+}
+
+// floodFillGenerator grows a single island out from the origin
+type floodFillGenerator struct{}
+
+func (floodFillGenerator) Generate(seed int64, params MapParams) (GeneratedMap, error) {
+	Seed(seed)
+	land, history, retries, err := generateIslandFloodFill(params.MinSize, params.MaxSize, IsometricCoordinate{})
+	if err != nil {
+		return GeneratedMap{}, err
+	}
+	world, err := finishMap(land, history, params.MapRadius)
+	if err != nil {
+		return GeneratedMap{}, err
+	}
+	world.Islands[0].Retries = retries
+	return world, nil
+}
+
+// perlinGenerator raises land wherever the height noise clears the water,
+// sweeping across the map a row at a time
+type perlinGenerator struct{}
+
+func (perlinGenerator) Generate(seed int64, params MapParams) (GeneratedMap, error) {
+	Seed(seed)
+	land := make([]*Tile, 0)
+	history := &GenLog{}
+	for x := -params.MapRadius; x < params.MapRadius; x++ {
+		for y := -params.MapRadius; y < params.MapRadius; y++ {
+			alt := getNoise(float64(x), float64(y)) * 3
+			if alt > WaterLevel {
+				coord := IsometricCoordinate{float64(x), float64(y), quantizeHeight(alt)}
+				land = append(land, &Tile{
+					Type:     pickTileType(coord),
+					Coord:    coord,
+					Walkable: true,
+				})
+				history.Add(coord, pickTileType(coord))
+			}
+		}
+		history.EndStep()
+	}
+	return finishMap(land, history, params.MapRadius)
+}
+
+// cellularGenerator scatters land at random, thinning toward the map edge,
+// then smooths it with a few rounds of cellular automata into rounded
+// islands and coves. The smoothing can erode everything away, so a seed
+// that leaves no land moves on to the next one.
+type cellularGenerator struct{}
+
+func (cellularGenerator) Generate(seed int64, params MapParams) (GeneratedMap, error) {
+	for reseed := int64(0); reseed < cellularReseeds; reseed++ {
+		Seed(seed + reseed)
+		generated, err := generateCellular(params)
+		if !errors.Is(err, errNoLand) {
+			return generated, err
+		}
+	}
+	return GeneratedMap{}, fmt.Errorf("seeds %d to %d: %w", seed, seed+cellularReseeds-1, errNoLand)
+}
+
+func generateCellular(params MapParams) (GeneratedMap, error) {
+	radius := params.MapRadius
+	size := 2 * radius
+	history := &GenLog{}
+	cellCoord := func(x, y int) IsometricCoordinate {
+		wx, wy := float64(x-radius), float64(y-radius)
+		return IsometricCoordinate{wx, wy, math.Max(WaterLevel, quantizeHeight(WaterLevel+getNoise(wx, wy)*3))}
+	}
+
+	cells := make([]bool, size*size)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			dist := math.Hypot(float64(x-radius), float64(y-radius)) / float64(radius)
+			if rand.Float64() < cellularFillChance*(1-dist*dist) {
+				cells[x*size+y] = true
+				coord := cellCoord(x, y)
+				history.Add(coord, pickTileType(coord))
+			}
+		}
+	}
+	history.EndStep()
+
+	for i := 0; i < cellularIterations; i++ {
+		next := make([]bool, len(cells))
+		for x := 0; x < size; x++ {
+			for y := 0; y < size; y++ {
+				neighbours := 0
+				for dx := -1; dx <= 1; dx++ {
+					for dy := -1; dy <= 1; dy++ {
+						nx, ny := x+dx, y+dy
+						if (dx != 0 || dy != 0) && nx >= 0 && nx < size && ny >= 0 && ny < size && cells[nx*size+ny] {
+							neighbours++
+						}
+					}
+				}
+				was := cells[x*size+y]
+				now := neighbours >= cellularBirth || (was && neighbours >= cellularSurvive)
+				next[x*size+y] = now
+				if now && !was {
+					coord := cellCoord(x, y)
+					history.Add(coord, pickTileType(coord))
+				} else if was && !now {
+					history.Remove(cellCoord(x, y))
+				}
+			}
+		}
+		cells = next
+		history.EndStep()
+	}
+
+	land := make([]*Tile, 0)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			if cells[x*size+y] {
+				coord := cellCoord(x, y)
+				land = append(land, &Tile{
+					Type:     pickTileType(coord),
+					Coord:    coord,
+					Walkable: true,
+				})
+			}
+		}
+	}
+	return finishMap(land, history, radius)
+}
+
+// finishMap floods the rest of the map, numbers the islands and picks a
+// spawn on the biggest one. A map without land has nowhere to spawn and
+// gives errNoLand.
+func finishMap(land []*Tile, history *GenLog, radius int) (GeneratedMap, error) {
+	tiles := GenerateMapFromTiles(land, radius)
+	byCoord := indexTiles(tiles)
+	islands := labelIslands(tiles, byCoord)
+	if len(islands) == 0 {
+		return GeneratedMap{}, errNoLand
+	}
+	finishIslands(tiles, byCoord, islands)
+	spawn := pickSpawn(tiles, byCoord, islands[0].ID)
+	return GeneratedMap{tiles, spawn, history, islands}, nil
+}
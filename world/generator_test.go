@@ -0,0 +1,88 @@
+package world
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestGeneratorsSpawnOnLand(t *testing.T) {
+	names := make([]string, 0, len(mapGenerators))
+	for name := range mapGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seeds := int64(12)
+	if testing.Short() {
+		seeds = 3
+	}
+	for _, name := range names {
+		gen := mapGenerators[name]
+		for seed := int64(0); seed < seeds; seed++ {
+			generated, err := gen.Generate(seed, DefaultMapParams)
+			if err != nil {
+				t.Errorf("%s seed %d: %v", name, seed, err)
+				continue
+			}
+			if len(generated.Islands) == 0 {
+				t.Errorf("%s seed %d: no islands", name, seed)
+			}
+			spawn := indexTiles(generated.Tiles)[IsometricCoordinate{X: generated.Spawn.X, Y: generated.Spawn.Y}]
+			if spawn == nil || spawn.Water || !spawn.Walkable {
+				t.Errorf("%s seed %d: spawn %v isn't on walkable land: %+v", name, seed, generated.Spawn, spawn)
+			}
+		}
+	}
+}
+
+func TestGeneratorsRepeatable(t *testing.T) {
+	for name, gen := range mapGenerators {
+		a, errA := gen.Generate(7, DefaultMapParams)
+		b, errB := gen.Generate(7, DefaultMapParams)
+		if errA != nil || errB != nil {
+			t.Fatalf("%s: %v, %v", name, errA, errB)
+		}
+		if len(a.Tiles) != len(b.Tiles) || a.Spawn != b.Spawn {
+			t.Errorf("%s: seed 7 gave %d tiles spawning at %v, then %d at %v", name, len(a.Tiles), a.Spawn, len(b.Tiles), b.Spawn)
+			continue
+		}
+		for i := range a.Tiles {
+			if *a.Tiles[i] != *b.Tiles[i] {
+				t.Errorf("%s: tile %d differs between runs: %+v, %+v", name, i, a.Tiles[i], b.Tiles[i])
+				break
+			}
+		}
+	}
+}
+
+func TestCellularRejectsEmptyMaps(t *testing.T) {
+	// too small a map for the edge falloff to leave any land standing
+	params := DefaultMapParams
+	params.MapRadius = 2
+	if _, err := (cellularGenerator{}).Generate(1, params); err == nil {
+		t.Errorf("expected an error for a map with no room for land")
+	}
+}
+
+func TestFloodFillEmptySizeRange(t *testing.T) {
+	params := DefaultMapParams
+	params.MinSize, params.MaxSize = 50, 50
+	if _, err := (floodFillGenerator{}).Generate(1, params); err == nil {
+		t.Errorf("expected an error for an empty island size range")
+	}
+}
+
+func TestArchipelagoPlacesEveryIsland(t *testing.T) {
+	generated, err := (archipelagoGenerator{}).Generate(3, DefaultMapParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(generated.Islands) != DefaultMapParams.Islands {
+		t.Errorf("got %d islands, asked for %d", len(generated.Islands), DefaultMapParams.Islands)
+	}
+
+	params := DefaultMapParams
+	params.Islands = 40
+	if _, err := (archipelagoGenerator{}).Generate(3, params); err == nil {
+		t.Errorf("expected an error fitting %d islands %.0f apart in radius %d", params.Islands, params.Spacing, params.MapRadius)
+	}
+}
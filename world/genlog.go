@@ -0,0 +1,134 @@
+package world
+
+// GenEvent is one change made while generating land: a tile raised at coord,
+// or taken away again
+type GenEvent struct {
+	coord    IsometricCoordinate
+	tileType TileType
+	removed  bool
+}
+
+// GenLog records generation as events grouped into steps, so any point in
+// the history can be rebuilt without keeping a copy of the map per step
+type GenLog struct {
+	events []GenEvent
+	// ends[i] is how many events had happened by the end of step i
+	ends []int
+}
+
+func (l *GenLog) Add(coord IsometricCoordinate, t TileType) {
+	l.events = append(l.events, GenEvent{coord: coord, tileType: t})
+}
+
+func (l *GenLog) Remove(coord IsometricCoordinate) {
+	l.events = append(l.events, GenEvent{coord: coord, removed: true})
+}
+
+// EndStep closes the current step; empty steps are dropped
+func (l *GenLog) EndStep() {
+	if len(l.ends) > 0 && l.ends[len(l.ends)-1] == len(l.events) || len(l.events) == 0 {
+		return
+	}
+	l.ends = append(l.ends, len(l.events))
+}
+
+func (l *GenLog) Steps() int {
+	return len(l.ends)
+}
+
+func (l *GenLog) Step(step int) []GenEvent {
+	start := 0
+	if step > 0 {
+		start = l.ends[step-1]
+	}
+	return l.events[start:l.ends[step]]
+}
+
+// Replay builds fresh land tiles as they stood at the end of step, in the
+// order they were first added
+func (l *GenLog) Replay(step int) []*Tile {
+	if step < 0 || len(l.ends) == 0 {
+		return nil
+	}
+	if step >= len(l.ends) {
+		step = len(l.ends) - 1
+	}
+	tiles := make([]*Tile, 0)
+	index := make(map[IsometricCoordinate]int)
+	for _, event := range l.events[:l.ends[step]] {
+		key := IsometricCoordinate{event.coord.X, event.coord.Y, 0}
+		idx, exists := index[key]
+		switch {
+		case event.removed && exists:
+			tiles[idx] = nil
+			delete(index, key)
+		case !event.removed && exists:
+			tiles[idx].Coord, tiles[idx].Type = event.coord, event.tileType
+		case !event.removed:
+			index[key] = len(tiles)
+			tiles = append(tiles, &Tile{Type: event.tileType, Coord: event.coord, Walkable: true})
+		}
+	}
+	land := tiles[:0]
+	for _, tile := range tiles {
+		if tile != nil {
+			land = append(land, tile)
+		}
+	}
+	return land
+}
+
+// GrowingMap plays a GenLog forward over a sea of water, changing its tiles
+// in place so each step only costs its own events. It's for watching the map
+// grow; the tiles aren't classified, measured or given biomes.
+type GrowingMap struct {
+	history *GenLog
+	tiles   []*Tile
+	byCoord map[IsometricCoordinate]*Tile
+	// events applied so far
+	applied int
+}
+
+func NewGrowingMap(history *GenLog, radius int) *GrowingMap {
+	m := &GrowingMap{
+		history: history,
+		byCoord: make(map[IsometricCoordinate]*Tile, 4*radius*radius),
+	}
+	for x := -radius; x < radius; x++ {
+		for y := -radius; y < radius; y++ {
+			tile := &Tile{Type: TILE_WATER, Coord: IsometricCoordinate{float64(x), float64(y), WaterLevel}, Water: true}
+			m.tiles = append(m.tiles, tile)
+			m.byCoord[IsometricCoordinate{float64(x), float64(y), 0}] = tile
+		}
+	}
+	return m
+}
+
+// Tiles is every tile on the map; the same tiles are updated as it grows
+func (m *GrowingMap) Tiles() []*Tile {
+	return m.tiles
+}
+
+// AdvanceTo applies events up to the end of step. It only goes forward,
+// asking for an earlier step than already shown does nothing.
+func (m *GrowingMap) AdvanceTo(step int) {
+	if step < 0 || len(m.history.ends) == 0 {
+		return
+	}
+	if step >= len(m.history.ends) {
+		step = len(m.history.ends) - 1
+	}
+	end := m.history.ends[step]
+	for ; m.applied < end; m.applied++ {
+		event := m.history.events[m.applied]
+		tile, ok := m.byCoord[IsometricCoordinate{event.coord.X, event.coord.Y, 0}]
+		if !ok {
+			continue
+		}
+		if event.removed {
+			*tile = Tile{Type: TILE_WATER, Coord: IsometricCoordinate{tile.Coord.X, tile.Coord.Y, WaterLevel}, Water: true}
+		} else {
+			*tile = Tile{Type: event.tileType, Coord: event.coord, Walkable: true}
+		}
+	}
+}
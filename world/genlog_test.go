@@ -0,0 +1,31 @@
+package world
+
+import "testing"
+
+// GrowingMap stepped forward has to show the same land as replaying the
+// log from scratch to each step
+func TestGrowingMapMatchesReplay(t *testing.T) {
+	generated, err := (archipelagoGenerator{}).Generate(5, DefaultMapParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	radius := DefaultMapParams.MapRadius
+	growing := NewGrowingMap(generated.History, radius)
+	if len(growing.Tiles()) != 4*radius*radius {
+		t.Fatalf("%d tiles, want %d", len(growing.Tiles()), 4*radius*radius)
+	}
+	steps := generated.History.Steps()
+	for _, step := range []int{0, 1, steps / 3, steps / 3, steps / 2, steps - 1} {
+		growing.AdvanceTo(step)
+		want := make(map[IsometricCoordinate]*Tile)
+		for _, tile := range GenerateMapFromTiles(generated.History.Replay(step), radius) {
+			want[IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y}] = tile
+		}
+		for _, tile := range growing.Tiles() {
+			other := want[IsometricCoordinate{X: tile.Coord.X, Y: tile.Coord.Y}]
+			if other == nil || other.Coord != tile.Coord || other.Type != tile.Type || other.Water != tile.Water {
+				t.Fatalf("step %d: growing map has %+v, replay has %+v", step, tile, other)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package world
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+    WaterLevel = 1
+
+    // noise shifts generateIslandFloodFill tries before giving up on an island
+    maxIslandRetries = 10000
+
+    TILE_LAND = "landTile"
+    TILE_WATER = "waterTile"
+    TILE_SAND = "sandTile"
+)
+
+// GenerateMapFromTiles fills the square of the given radius around the
+// origin with water wherever tiles doesn't have land
+func GenerateMapFromTiles(tiles []*Tile, radius int) ([]*Tile) {
+    // make map have water
+    tileTypes := make(map[IsometricCoordinate]*Tile)
+    for _, tile := range tiles {
+        tileTypes[IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}] = tile
+    }
+    finalTiles := make([]*Tile, 0)
+    for x := -radius; x<radius; x++ {
+        for y := -radius; y<radius; y++ {
+            if tile, p := tileTypes[IsometricCoordinate{float64(x), float64(y), 0}]; p {
+                finalTiles = append(finalTiles, tile)
+            } else {
+                finalTiles = append(finalTiles, &Tile{
+                    Type: TILE_WATER,
+                    Coord: IsometricCoordinate{float64(x), float64(y), WaterLevel},
+                    Water: true,
+                })
+            }
+        }
+    }
+    classifyTerrain(finalTiles)
+    measureWaterDepth(finalTiles)
+    return finalTiles
+}
+
+// measureWaterDepth sets each water tile's depth to its distance in steps
+// from the nearest land, walking out from every shore at once
+func measureWaterDepth(tiles []*Tile) {
+    byCoord := make(map[IsometricCoordinate]*Tile, len(tiles))
+    queue := make([]*Tile, 0)
+    for _, tile := range tiles {
+        byCoord[IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}] = tile
+        tile.Depth = 0
+        if !tile.Water {
+            queue = append(queue, tile)
+        }
+    }
+    for len(queue) > 0 {
+        tile := queue[0]
+        queue = queue[1:]
+        for _, adj := range AdjIsometric(IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}) {
+            if neighbor, ok := byCoord[adj]; ok && neighbor.Water && neighbor.Depth == 0 {
+                neighbor.Depth = tile.Depth + 1
+                queue = append(queue, neighbor)
+            }
+        }
+    }
+}
+
+func pickTileType(position IsometricCoordinate) TileType {
+    if position.Z < WaterLevel*1.1 {
+        return TILE_SAND 
+    }
+    return TILE_LAND 
+}
+
+// _generateIslandFloodFill grows an island outward from the origin, highest
+// ground first, sampling noise shifted by noiseOffset
+func _generateIslandFloodFill(minSize, maxSize int, noiseOffset IsometricCoordinate) ([]*Tile, bool, *GenLog) {
+    tiles := make([]*Tile, 0)
+    history := &GenLog{}
+    tilesNeedNeighbor := make([]*Tile, 0)
+    tilesTaken := make(map[IsometricCoordinate]bool)
+    firstTile := true
+    for {
+        if len(tiles) > maxSize {
+            return nil, false, nil
+            // return tiles, true, steps
+        }
+        if len(tilesNeedNeighbor) == 0 && !firstTile {
+            break
+        }
+        var seedPos IsometricCoordinate
+        if len(tiles) == 0 {
+            seedPos = IsometricCoordinate{0, 0, 0}
+        } else {
+            sort.Slice(tilesNeedNeighbor, func(i, j int) bool {
+                return tilesNeedNeighbor[i].Coord.Z > tilesNeedNeighbor[j].Coord.Z
+            })
+            seedPos = tilesNeedNeighbor[0].Coord
+        }
+        seedPos = IsometricCoordinate{seedPos.X, seedPos.Y, 0}
+        adj := AdjIsometric(seedPos)
+        var coordAdding IsometricCoordinate
+        adding := false
+        for _, coord := range adj {
+            if _, p := tilesTaken[coord]; !p {
+                coordAdding = coord
+                adding = true
+                break
+            }
+        }
+        firstTile = false
+        if !adding {
+            if len(tilesNeedNeighbor) >= 1 {
+                tilesNeedNeighbor = tilesNeedNeighbor[1:]
+            }
+            continue
+        }
+        alt := getNoise(coordAdding.X+noiseOffset.X, coordAdding.Y+noiseOffset.Y)*3+WaterLevel
+        if alt > WaterLevel {
+            finalTileCoord := IsometricCoordinate{
+                X: coordAdding.X,
+                Y: coordAdding.Y,
+                Z: quantizeHeight(alt-WaterLevel*0.2),
+            } 
+            tile := &Tile{
+                Type: pickTileType(finalTileCoord),
+                Coord: finalTileCoord,
+                Walkable: true,
+            }
+            tiles = append(tiles, tile)
+            history.Add(tile.Coord, tile.Type)
+            history.EndStep()
+            tilesNeedNeighbor = append(tilesNeedNeighbor, tile)
+        }
+        tilesTaken[coordAdding] = true
+    }
+    if minSize < len(tiles) && len(tiles) < maxSize {
+        return tiles, true, history
+    }
+    return nil, false, nil
+}
+
+// generateIslandFloodFill retries with the noise shifted until an island
+// lands in the size range, returning how many retries it took
+func generateIslandFloodFill(minSize, maxSize int, noiseOffset IsometricCoordinate) ([]*Tile, *GenLog, int, error) {
+    if minSize >= maxSize {
+        return nil, nil, 0, fmt.Errorf("island size range %d-%d is empty", minSize, maxSize)
+    }
+    for retries := 0; retries < maxIslandRetries; retries++ {
+        if tiles, valid, history := _generateIslandFloodFill(minSize, maxSize, noiseOffset); valid {
+            return tiles, history, retries, nil
+        }
+        noiseOffset.X += 17.0/13.0
+        noiseOffset.Y += 17.0/13.0
+    }
+    return nil, nil, maxIslandRetries, fmt.Errorf("no island between %d and %d tiles in %d tries", minSize, maxSize, maxIslandRetries)
+}
@@ -0,0 +1,23 @@
+package world
+
+type FoliageType int
+
+const (
+	FOLIAGE_GRASS = 0
+	FOLIAGE_TREE  = 1
+)
+
+type ScrapType int
+
+const (
+	SCRAP_SCRAP = iota
+	SCRAP_WIRE
+	SCRAP_ELEC
+)
+
+// DefaultScrapTable is for shores whose biome doesn't set its own, commonest
+// first
+var DefaultScrapTable = NewWeightedTable[ScrapType]().
+	Add(SCRAP_SCRAP, 2).
+	Add(SCRAP_WIRE, 1).
+	Add(SCRAP_ELEC, 1)
@@ -0,0 +1,82 @@
+package world
+
+import "math"
+
+// heights snap to multiples of HeightStep, one tile cube tall, so
+// neighbours are either level, one step apart (a slope) or a cliff
+const HeightStep = 1.0
+
+type SlopeDir int
+
+const (
+	SLOPE_NONE SlopeDir = iota
+	// named for the neighbour the slope rises towards
+	SLOPE_UP_NEG_X
+	SLOPE_UP_NEG_Y
+	SLOPE_UP_POS_X
+	SLOPE_UP_POS_Y
+)
+
+var SlopeNames = map[string]SlopeDir{
+	"-x": SLOPE_UP_NEG_X,
+	"-y": SLOPE_UP_NEG_Y,
+	"+x": SLOPE_UP_POS_X,
+	"+y": SLOPE_UP_POS_Y,
+}
+
+func quantizeHeight(z float64) float64 {
+	return math.Round(z/HeightStep) * HeightStep
+}
+
+func heightLevel(z float64) int {
+	return int(math.Round(z / HeightStep))
+}
+
+// HeightAt is the ground height at x, y within the tile, ramping up towards
+// the high side on slopes
+func (t *Tile) HeightAt(x, y float64) float64 {
+	switch t.Slope {
+	case SLOPE_UP_NEG_X:
+		return t.Coord.Z + HeightStep*(0.5-(x-t.Coord.X))
+	case SLOPE_UP_POS_X:
+		return t.Coord.Z + HeightStep*(0.5+(x-t.Coord.X))
+	case SLOPE_UP_NEG_Y:
+		return t.Coord.Z + HeightStep*(0.5-(y-t.Coord.Y))
+	case SLOPE_UP_POS_Y:
+		return t.Coord.Z + HeightStep*(0.5+(y-t.Coord.Y))
+	}
+	return t.Coord.Z
+}
+
+// classifyTerrain turns land tiles one step below a neighbour into slopes
+// up to it. Bigger drops stay as cliffs, which the step height limit in
+// CollisionWorld keeps the player from climbing.
+func classifyTerrain(tiles []*Tile) {
+	byCoord := make(map[IsometricCoordinate]*Tile, len(tiles))
+	for _, tile := range tiles {
+		byCoord[IsometricCoordinate{tile.Coord.X, tile.Coord.Y, 0}] = tile
+	}
+	neighbors := []struct {
+		dx, dy float64
+		slope  SlopeDir
+	}{
+		{-1, 0, SLOPE_UP_NEG_X},
+		{0, -1, SLOPE_UP_NEG_Y},
+		{1, 0, SLOPE_UP_POS_X},
+		{0, 1, SLOPE_UP_POS_Y},
+	}
+	for _, tile := range tiles {
+		tile.Slope = SLOPE_NONE
+		if !tile.Walkable {
+			continue
+		}
+		level := heightLevel(tile.Coord.Z)
+		for _, n := range neighbors {
+			neighbor, ok := byCoord[IsometricCoordinate{tile.Coord.X + n.dx, tile.Coord.Y + n.dy, 0}]
+			if ok && neighbor.Walkable && heightLevel(neighbor.Coord.Z) == level+1 {
+				tile.Slope = n.slope
+				break
+			}
+		}
+	}
+}
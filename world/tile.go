@@ -0,0 +1,33 @@
+package world
+
+import "math"
+
+type TileType string
+
+type Tile struct {
+	Type     TileType
+	Coord    IsometricCoordinate
+	Walkable bool
+	Slope    SlopeDir
+	Water    bool
+	// water tiles' distance from the nearest land, 1 right off the shore
+	Depth int
+	// id of the IslandInfo a land tile belongs to, 0 for none
+	Island int
+	// set by BiomeTable.Assign, nil until then
+	Biome *Biome
+}
+
+// CollidesWith is true when c's x, y falls on the tile's footprint, which is
+// centred on its coordinate
+func (t *Tile) CollidesWith(c IsometricCoordinate) bool {
+	return t.Coord.X-0.5 <= c.X && c.X < t.Coord.X+0.5 &&
+		t.Coord.Y-0.5 <= c.Y && c.Y < t.Coord.Y+0.5
+}
+
+// TileCenter is the whole coordinate of the tile whose footprint holds v.
+// Footprints are half-open like CollidesWith's, so -0.5 belongs to tile 0,
+// where math.Round would give -1.
+func TileCenter(v float64) float64 {
+	return math.Floor(v + 0.5)
+}
@@ -0,0 +1,39 @@
+package world
+
+import (
+	"math/rand"
+
+	"github.com/aquilax/go-perlin"
+)
+
+type IsometricCoordinate struct {
+    X, Y, Z float64
+}
+
+func AdjIsometric(i IsometricCoordinate) []IsometricCoordinate {
+    // assumes int passed in, i.e. applies offset of 1
+    return []IsometricCoordinate{
+        {i.X+1, i.Y, i.Z},
+        {i.X-1, i.Y, i.Z},
+        {i.X, i.Y+1, i.Z},
+        {i.X, i.Y-1, i.Z},
+    }
+}
+
+var perlinGen, moistureGen *perlin.Perlin
+
+// Seed makes map generation and every later random roll repeatable
+func Seed(seed int64) {
+    perlinGen = perlin.NewPerlin(2, 2, 3, seed)
+    moistureGen = perlin.NewPerlin(2, 2, 3, seed+1)
+    rand.Seed(seed)
+}
+
+func getNoise(x, y float64) float64 {
+    return perlinGen.Noise2D(x/13, y/13)
+}
+
+// getMoisture is a second, broader noise field for picking biomes
+func getMoisture(x, y float64) float64 {
+    return moistureGen.Noise2D(x/29, y/29)
+}
@@ -0,0 +1,91 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// WeightedTable picks items at random in proportion to their weights. Items
+// keep the order they were added in, so a roll skewed toward 1 favours the
+// later ones.
+type WeightedTable[T any] struct {
+	items []T
+	// cumulative[i] is the total weight of items[:i+1]
+	cumulative []float64
+}
+
+func NewWeightedTable[T any]() *WeightedTable[T] {
+	return &WeightedTable[T]{}
+}
+
+// Add appends item; weights at or below zero are never picked
+func (t *WeightedTable[T]) Add(item T, weight float64) *WeightedTable[T] {
+	if weight < 0 {
+		weight = 0
+	}
+	t.items = append(t.items, item)
+	t.cumulative = append(t.cumulative, t.Total()+weight)
+	return t
+}
+
+func (t *WeightedTable[T]) Len() int {
+	return len(t.items)
+}
+
+func (t *WeightedTable[T]) Total() float64 {
+	if len(t.cumulative) == 0 {
+		return 0
+	}
+	return t.cumulative[len(t.cumulative)-1]
+}
+
+// PickRoll maps roll, in [0, 1), onto the table. An empty or weightless
+// table gives the zero value.
+func (t *WeightedTable[T]) PickRoll(roll float64) T {
+	var zero T
+	total := t.Total()
+	if total <= 0 {
+		return zero
+	}
+	target := roll * total
+	idx := sort.Search(len(t.cumulative), func(i int) bool {
+		return t.cumulative[i] > target
+	})
+	if idx == len(t.items) {
+		// roll of 1 or float error at the very top
+		idx = len(t.items) - 1
+		for idx > 0 && t.cumulative[idx] == t.cumulative[idx-1] {
+			idx--
+		}
+	}
+	return t.items[idx]
+}
+
+func (t *WeightedTable[T]) Pick() T {
+	return t.PickRoll(rand.Float64())
+}
+
+// weightedTableFromNames builds a table from a name to weight map as read from
+// JSON, commonest first so skewed rolls land on the rarer items; names are
+// looked up in lookup
+func weightedTableFromNames[T any](weights map[string]float64, lookup map[string]T, what string) (*WeightedTable[T], error) {
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		if _, ok := lookup[name]; !ok {
+			return nil, fmt.Errorf("unknown %s %q", what, name)
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if weights[names[i]] != weights[names[j]] {
+			return weights[names[i]] > weights[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	table := NewWeightedTable[T]()
+	for _, name := range names {
+		table.Add(lookup[name], weights[name])
+	}
+	return table, nil
+}
@@ -0,0 +1,76 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWeightedTableDistribution(t *testing.T) {
+	table := NewWeightedTable[string]().
+		Add("common", 6).
+		Add("uncommon", 3).
+		Add("rare", 1)
+	rng := rand.New(rand.NewSource(1))
+	const picks = 100000
+	counts := make(map[string]int)
+	for i := 0; i < picks; i++ {
+		counts[table.PickRoll(rng.Float64())]++
+	}
+	for item, weight := range map[string]float64{"common": 6, "uncommon": 3, "rare": 1} {
+		want := weight / table.Total()
+		got := float64(counts[item]) / picks
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("%s picked %.3f of the time, want %.3f", item, got, want)
+		}
+	}
+}
+
+func TestWeightedTableZeroWeights(t *testing.T) {
+	table := NewWeightedTable[string]().
+		Add("never", 0).
+		Add("always", 1).
+		Add("negative", -5).
+		Add("trailing", 0)
+	if table.Total() != 1 {
+		t.Errorf("total is %v, want 1", table.Total())
+	}
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 1000; i++ {
+		if got := table.PickRoll(rng.Float64()); got != "always" {
+			t.Fatalf("picked %q, only always has any weight", got)
+		}
+	}
+	// the very top of the range still lands on a weighted item
+	if got := table.PickRoll(1); got != "always" {
+		t.Errorf("roll of 1 picked %q", got)
+	}
+}
+
+func TestWeightedTableEmpty(t *testing.T) {
+	empty := NewWeightedTable[string]()
+	if empty.Len() != 0 || empty.Total() != 0 {
+		t.Errorf("empty table has %d items weighing %v", empty.Len(), empty.Total())
+	}
+	if got := empty.PickRoll(0.5); got != "" {
+		t.Errorf("empty table picked %q", got)
+	}
+	weightless := NewWeightedTable[int]().Add(3, 0)
+	if got := weightless.PickRoll(0.5); got != 0 {
+		t.Errorf("weightless table picked %d", got)
+	}
+}
+
+func TestWeightedTableFromNames(t *testing.T) {
+	table, err := weightedTableFromNames(map[string]float64{"wire": 1, "scrap": 2}, ScrapTypeNames, "scrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// commonest first, so a low roll gets the common item
+	if got := table.PickRoll(0); got != SCRAP_SCRAP {
+		t.Errorf("lowest roll picked %v, want scrap", got)
+	}
+	if _, err := weightedTableFromNames(map[string]float64{"gold": 1}, ScrapTypeNames, "scrap"); err == nil {
+		t.Errorf("expected an error for an unknown name")
+	}
+}